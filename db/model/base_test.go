@@ -17,75 +17,78 @@ package model
 
 import (
 	"context"
-	"runtime/debug"
-	"sync"
+	"testing"
 
 	"github.com/opencord/voltha-protos/v3/go/voltha"
 )
 
-var callbackMutex sync.Mutex
-
-func commonChanCallback(ctx context.Context, args ...interface{}) interface{} {
-	logger.Infof("Running common callback - arg count: %d", len(args))
-
-	//for i := 0; i < len(args); i++ {
-	//	logger.Infof("ARG %d : %+v", i, args[i])
-	//}
-
-	callbackMutex.Lock()
-	defer callbackMutex.Unlock()
-
-	execDoneChan := args[1].(*chan struct{})
-
-	// Inform the caller that the callback was executed
-	if *execDoneChan != nil {
-		logger.Infof("Sending completion indication - stack:%s", string(debug.Stack()))
-		close(*execDoneChan)
-		*execDoneChan = nil
+// The callbacks below exercised the legacy args ...interface{} wiring with unchecked type
+// assertions (execDoneChan := args[1].(*chan struct{}), id := args[1].(*voltha.Device), ...).
+// They are migrated here onto CallbackRegistry[T], so a bad wiring fails to compile instead of
+// panicking on a bad assertion at invocation time.
+
+// TestCallbackRegistryClosesDoneChannel migrates commonChanCallback: a callback that signals
+// completion by closing a channel handed to it as the event, instead of unchecked-asserting it
+// out of args[1].
+func TestCallbackRegistryClosesDoneChannel(t *testing.T) {
+	registry := NewCallbackRegistry[chan struct{}]("POST_ADD")
+	registry.Register(func(ctx context.Context, done chan struct{}) error {
+		close(done)
+		return nil
+	})
+
+	done := make(chan struct{})
+	if err := registry.Invoke(context.Background(), "/devices/device-1", done); err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-
-	return nil
-}
-
-func commonCallback2(ctx context.Context, args ...interface{}) interface{} {
-	logger.Infof("Running common2 callback - arg count: %d %+v", len(args), args)
-
-	return nil
-}
-
-func commonCallbackFunc(ctx context.Context, args ...interface{}) interface{} {
-	logger.Infof("Running common callback - arg count: %d", len(args))
-
-	for i := 0; i < len(args); i++ {
-		logger.Infof("ARG %d : %+v", i, args[i])
+	select {
+	case <-done:
+	default:
+		t.Fatal("expected the callback to close the done channel")
 	}
-	execStatusFunc := args[1].(func(bool))
-
-	// Inform the caller that the callback was executed
-	execStatusFunc(true)
-
-	return nil
 }
 
-func firstCallback(ctx context.Context, args ...interface{}) interface{} {
-	name := args[0]
-	id := args[1]
-	logger.Infof("Running first callback - name: %s, id: %s\n", name, id)
-	return nil
+// TestCallbackRegistryInvokesStatusFunc migrates commonCallbackFunc: a callback that reports its
+// own execution status through a func(bool) handed to it as the event.
+func TestCallbackRegistryInvokesStatusFunc(t *testing.T) {
+	registry := NewCallbackRegistry[func(bool)]("POST_UPDATE")
+	var reported bool
+	registry.Register(func(ctx context.Context, report func(bool)) error {
+		report(true)
+		return nil
+	})
+
+	if err := registry.Invoke(context.Background(), "/devices/device-1", func(ok bool) { reported = ok }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reported {
+		t.Fatal("expected the callback to report status")
+	}
 }
 
-func secondCallback(ctx context.Context, args ...interface{}) interface{} {
-	name := args[0].(map[string]string)
-	id := args[1]
-	logger.Infof("Running second callback - name: %s, id: %f\n", name["name"], id)
-	// FIXME: the panic call seem to interfere with the logging mechanism
-	//panic("Generating a panic in second callback")
-	return nil
+// namedDeviceEvent bundles what firstCallback/secondCallback/thirdCallback used to pull out of
+// args[0]/args[1] by position and unchecked type assertion.
+type namedDeviceEvent struct {
+	Name   string
+	Device *voltha.Device
 }
 
-func thirdCallback(ctx context.Context, args ...interface{}) interface{} {
-	name := args[0]
-	id := args[1].(*voltha.Device)
-	logger.Infof("Running third callback - name: %+v, id: %s\n", name, id.Id)
-	return nil
+// TestCallbackRegistryInvokesWithNamedDeviceEvent migrates firstCallback/secondCallback/
+// thirdCallback into a single typed event, since CallbackRegistry[T] invokes with one event value
+// rather than a variadic args list.
+func TestCallbackRegistryInvokesWithNamedDeviceEvent(t *testing.T) {
+	registry := NewCallbackRegistry[namedDeviceEvent]("POST_UPDATE")
+	var got namedDeviceEvent
+	registry.Register(func(ctx context.Context, event namedDeviceEvent) error {
+		got = event
+		return nil
+	})
+
+	event := namedDeviceEvent{Name: "first", Device: &voltha.Device{Id: "device-1"}}
+	if err := registry.Invoke(context.Background(), "/devices/device-1", event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "first" || got.Device.Id != "device-1" {
+		t.Fatalf("callback was not invoked with the expected event: %+v", got)
+	}
 }
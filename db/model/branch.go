@@ -15,15 +15,27 @@
  */
 package model
 
-// TODO: implement weak references or something equivalent
+import (
+	"fmt"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+)
+
 // TODO: missing proper logging
 
+// Branch tracks one line of revisions for a node - either the node's persistent "NONE" txid
+// branch or a short-lived branch opened for an in-flight transaction (see Txid). Revisions is
+// paged through an optional RevisionStore rather than kept resident forever: store is nil for a
+// Branch that hasn't opted into paging (the previous always-in-memory behavior), and autoPrune
+// gates whether GC() is allowed to evict hashes this branch no longer references.
 type Branch struct {
 	Node      *node
 	Txid      string
 	Origin    Revision
 	Revisions map[string]Revision
 	Latest    Revision
+	autoPrune bool
+	store     RevisionStore
 }
 
 func NewBranch(node *node, txid string, origin Revision, autoPrune bool) *Branch {
@@ -33,17 +45,59 @@ func NewBranch(node *node, txid string, origin Revision, autoPrune bool) *Branch
 	cb.Origin = origin
 	cb.Revisions = make(map[string]Revision)
 	cb.Latest = origin
+	cb.autoPrune = autoPrune
 
 	return cb
 }
 
-// TODO: Check if the following are required
+// SetRevisionStore opts this branch into paging Revisions out to store: get() consults store on
+// a local miss, and GC() writes each revision it evicts from memory (one that no branch's Latest
+// or Origin still references) to store before dropping it, so it can still be paged back in by
+// hash. Called once, after NewBranch, by whichever code
+// path wires a node up to a backing KV store.
+func (cb *Branch) SetRevisionStore(store RevisionStore) {
+	cb.store = store
+}
+
+// get returns the revision for hash, paging it in from cb.store on a local miss. The paged-in
+// revision is cached back into cb.Revisions so a repeated get for the same hash doesn't round
+// trip to the store again.
 func (cb *Branch) get(hash string) Revision {
-	return cb.Revisions[hash]
+	if rev, have := cb.Revisions[hash]; have {
+		return rev
+	}
+	if cb.store == nil {
+		return nil
+	}
+	rev, found, err := cb.store.LoadRevision(hash)
+	if err != nil {
+		log.Warnw("branch-revision-load-failed", log.Fields{"txid": cb.Txid, "hash": hash, "error": err})
+		return nil
+	}
+	if !found {
+		return nil
+	}
+	cb.Revisions[hash] = rev
+	return rev
 }
+
 func (cb *Branch) GetLatest() Revision {
 	return cb.Latest
 }
 func (cb *Branch) GetOrigin() Revision {
 	return cb.Origin
+}
+
+// set records rev under hash in cb.Revisions and, if this branch is backed by a RevisionStore,
+// persists it there too so it survives a restart and so GC() can page it back out of memory
+// later without losing it.
+func (cb *Branch) set(hash string, rev Revision) error {
+	cb.Revisions[hash] = rev
+	if cb.store == nil {
+		return nil
+	}
+	if err := cb.store.SaveRevision(hash, rev); err != nil {
+		return fmt.Errorf("branch-revision-save-failed-%s-%s: %w", cb.Txid, hash, err)
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package model
+
+import "github.com/opencord/voltha-lib-go/v3/pkg/log"
+
+// GC evicts revisions from cb.Revisions that are no longer reachable from any branch's Latest or
+// Origin pointer on cb.Node - the copy-on-write history accumulates one Revision per mutation,
+// and without this a long-running core keeps every one of them in memory for as long as the node
+// exists. GC is a no-op unless cb was constructed with autoPrune true.
+//
+// An evicted revision is not necessarily lost: if cb has a RevisionStore (SetRevisionStore), GC
+// pages the revision out to it before dropping it from memory, and Branch.get transparently pages
+// it back in on the next lookup by hash.
+func (cb *Branch) GC() {
+	if !cb.autoPrune {
+		return
+	}
+	live := liveHashes(cb.Node)
+	for hash, rev := range cb.Revisions {
+		if _, keep := live[hash]; keep {
+			continue
+		}
+		if cb.store != nil {
+			if err := cb.store.SaveRevision(hash, rev); err != nil {
+				log.Warnw("branch-gc-archive-failed", log.Fields{"txid": cb.Txid, "hash": hash, "error": err})
+				continue // keep it in memory rather than lose it if the archive write failed
+			}
+		}
+		delete(cb.Revisions, hash)
+	}
+}
+
+// liveHashes returns the hash of every branch's Latest and Origin revision on n, i.e. the set of
+// revisions a reference-counted GC must never collect regardless of which branch's Revisions map
+// they happen to sit in - a revision can be the Origin of one in-flight Txid branch while no
+// longer appearing in the NONE branch's own Latest/Origin pair.
+func liveHashes(n *node) map[string]struct{} {
+	live := make(map[string]struct{})
+	for _, b := range n.Branches {
+		if b.Latest != nil {
+			live[b.Latest.GetHash()] = struct{}{}
+		}
+		if b.Origin != nil {
+			live[b.Origin.GetHash()] = struct{}{}
+		}
+	}
+	return live
+}
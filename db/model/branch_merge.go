@@ -0,0 +1,157 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package model
+
+import "fmt"
+
+// RevisionDelta is one hash Branch.Diff found on only one side of the two branches it compared.
+type RevisionDelta struct {
+	Hash     string
+	Revision Revision
+	InOther  bool // true if Hash is present in the branch passed to Diff, false if only in the receiver
+}
+
+// Diff reports every revision hash known to cb or other but not both. It does not itself decide
+// how to reconcile them - that's Merge's job - Diff is the read-only building block transaction
+// code can use to show a caller what a pending Merge would actually change.
+func (cb *Branch) Diff(other *Branch) ([]RevisionDelta, error) {
+	if other == nil {
+		return nil, fmt.Errorf("diff-%s: other branch is nil", cb.Txid)
+	}
+	var deltas []RevisionDelta
+	for hash, rev := range cb.Revisions {
+		if _, inOther := other.Revisions[hash]; !inOther {
+			deltas = append(deltas, RevisionDelta{Hash: hash, Revision: rev, InOther: false})
+		}
+	}
+	for hash, rev := range other.Revisions {
+		if _, inSelf := cb.Revisions[hash]; !inSelf {
+			deltas = append(deltas, RevisionDelta{Hash: hash, Revision: rev, InOther: true})
+		}
+	}
+	return deltas, nil
+}
+
+// MergeStrategy selects how Branch.Merge reconciles cb with another branch that diverged from it,
+// typically a transaction branch opened against the same Txid's origin.
+type MergeStrategy int
+
+const (
+	// MergeFastForward requires other to have branched from cb's current Latest with no further
+	// changes on cb's side since; it just adopts other.Latest as cb's new Latest.
+	MergeFastForward MergeStrategy = iota
+	// MergeThreeWay requires cb and other to share a common Origin. If only one side changed
+	// since then, that side's Latest wins; if both changed, it fails with a MergeConflictError
+	// rather than guessing which side's change should take precedence.
+	MergeThreeWay
+	// MergeReportConflicts behaves like MergeThreeWay but, on conflict, returns via
+	// MergeConflictError cb.Latest's hash plus every hash Diff finds on only one of the two
+	// branches instead of just the tip, so a caller can decide how to resolve field-by-field
+	// instead of only learning that *something* conflicted.
+	MergeReportConflicts
+)
+
+// MergeConflictError is returned by Merge when strategy could not pick a winner automatically.
+// Hashes is always at least cb.Latest's hash; MergeReportConflicts additionally walks Diff to
+// add every hash known to only one of the two branches.
+type MergeConflictError struct {
+	Hashes []string
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("merge-conflict: both sides changed since the common origin, %d revision(s) reported", len(e.Hashes))
+}
+
+// Merge reconciles cb with other per strategy and, on success, updates cb.Latest (and persists it
+// via cb.set if cb has a RevisionStore) to the resolved revision, returning it. cb is left
+// unchanged if strategy cannot resolve the merge.
+func (cb *Branch) Merge(other *Branch, strategy MergeStrategy) (Revision, error) {
+	if other == nil {
+		return nil, fmt.Errorf("merge-%s: other branch is nil", cb.Txid)
+	}
+	switch strategy {
+	case MergeFastForward:
+		return cb.mergeFastForward(other)
+	case MergeThreeWay:
+		return cb.mergeThreeWay(other)
+	case MergeReportConflicts:
+		return cb.mergeReportConflicts(other)
+	default:
+		return nil, fmt.Errorf("merge-%s: unknown strategy %d", cb.Txid, strategy)
+	}
+}
+
+func (cb *Branch) mergeFastForward(other *Branch) (Revision, error) {
+	if cb.Latest == nil || other.Origin == nil || other.Origin.GetHash() != cb.Latest.GetHash() {
+		return nil, fmt.Errorf("merge-%s: fast-forward not possible, branches have diverged", cb.Txid)
+	}
+	return cb.adopt(other.Latest)
+}
+
+func (cb *Branch) mergeThreeWay(other *Branch) (Revision, error) {
+	if cb.Origin == nil || other.Origin == nil || cb.Origin.GetHash() != other.Origin.GetHash() {
+		return nil, fmt.Errorf("merge-%s: three-way merge requires a common origin", cb.Txid)
+	}
+	aChanged := cb.Latest.GetHash() != cb.Origin.GetHash()
+	bChanged := other.Latest.GetHash() != other.Origin.GetHash()
+	switch {
+	case !bChanged:
+		return cb.Latest, nil
+	case !aChanged:
+		return cb.adopt(other.Latest)
+	default:
+		return nil, &MergeConflictError{Hashes: []string{cb.Latest.GetHash()}}
+	}
+}
+
+func (cb *Branch) mergeReportConflicts(other *Branch) (Revision, error) {
+	rev, err := cb.mergeThreeWay(other)
+	if err == nil {
+		return rev, nil
+	}
+	var conflict *MergeConflictError
+	if ce, ok := err.(*MergeConflictError); ok {
+		conflict = ce
+	} else {
+		return nil, err
+	}
+
+	deltas, diffErr := cb.Diff(other)
+	if diffErr != nil {
+		return nil, conflict
+	}
+	hashes := make(map[string]struct{}, len(conflict.Hashes))
+	for _, h := range conflict.Hashes {
+		hashes[h] = struct{}{}
+	}
+	for _, d := range deltas {
+		hashes[d.Hash] = struct{}{}
+	}
+	all := make([]string, 0, len(hashes))
+	for h := range hashes {
+		all = append(all, h)
+	}
+	return nil, &MergeConflictError{Hashes: all}
+}
+
+// adopt sets cb.Latest to rev, persisting it through cb.store if one is configured.
+func (cb *Branch) adopt(rev Revision) (Revision, error) {
+	if err := cb.set(rev.GetHash(), rev); err != nil {
+		return nil, err
+	}
+	cb.Latest = rev
+	return cb.Latest, nil
+}
@@ -0,0 +1,203 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package model
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// CallbackError reports a typed callback that panicked (Cause is the recovered value, Stack its
+// stack) or that was abandoned because its timeout or the caller's context expired (Cause is the
+// context error, Stack is empty), so that the invoker gets a regular error instead of a crashed
+// goroutine.
+type CallbackError struct {
+	Kind  string
+	Cause interface{}
+	Stack string
+}
+
+func (e *CallbackError) Error() string {
+	return fmt.Sprintf("callback-%s-failed: %v", e.Kind, e.Cause)
+}
+
+// Callback is a typed event handler registered against a CallbackRegistry. Unlike the legacy
+// args ...interface{} callbacks, T is known at registration time so a bad wiring fails to
+// compile instead of panicking on an unchecked type assertion.
+type Callback[T any] func(ctx context.Context, event T) error
+
+// CancellationMode controls how Invoke treats a callback once its execution policy's timeout or
+// the caller's own context expires.
+type CancellationMode int
+
+const (
+	// WaitForResult blocks Invoke until the callback returns, times out, or is cancelled.
+	WaitForResult CancellationMode = iota
+	// FireAndForget lets the callback keep running in the background; Invoke moves on to the
+	// next registered callback (or returns) without waiting for it.
+	FireAndForget
+)
+
+// ExecutionPolicy bounds how a single registered callback is allowed to run so that one slow or
+// wedged subscriber (e.g. a change-event stream consumer) cannot stall the model writer that
+// triggered it.
+type ExecutionPolicy struct {
+	// Timeout is the maximum time the callback gets to run. Zero means no timeout.
+	Timeout time.Duration
+	// MaxConcurrency bounds how many invocations of this callback may be in flight at once
+	// across all keys. Zero means unbounded.
+	MaxConcurrency int
+	Cancellation   CancellationMode
+}
+
+// DefaultExecutionPolicy is used by Register, matching today's behaviour: wait for the callback,
+// no timeout, no concurrency cap.
+var DefaultExecutionPolicy = ExecutionPolicy{Cancellation: WaitForResult}
+
+type registeredCallback[T any] struct {
+	cb     Callback[T]
+	policy ExecutionPolicy
+	sem    chan struct{}
+}
+
+// CallbackRegistry keeps the ordered list of typed callbacks registered for a single event kind
+// (e.g. POST_UPDATE, POST_ADD) and invokes them with panic containment. Execution is serialized
+// per invocation key (typically the model path) rather than behind one global callbackMutex, so
+// a callback running against "/devices/a" never blocks one running against "/devices/b".
+type CallbackRegistry[T any] struct {
+	kind       string
+	callbacks  []*registeredCallback[T]
+	keyMutexes sync.Map // string -> *sync.Mutex
+}
+
+// NewCallbackRegistry creates an empty registry for the given event kind. kind is only used for
+// logging/error-reporting so callers can tell which registry a recovered panic came from.
+func NewCallbackRegistry[T any](kind string) *CallbackRegistry[T] {
+	return &CallbackRegistry[T]{kind: kind}
+}
+
+// Register appends cb to the set of callbacks invoked by Invoke, using DefaultExecutionPolicy.
+func (r *CallbackRegistry[T]) Register(cb Callback[T]) {
+	r.RegisterWithPolicy(cb, DefaultExecutionPolicy)
+}
+
+// RegisterWithPolicy appends cb with an explicit ExecutionPolicy, e.g. to give a known-slow
+// subscriber a timeout and let the writer carry on without it (FireAndForget).
+func (r *CallbackRegistry[T]) RegisterWithPolicy(cb Callback[T], policy ExecutionPolicy) {
+	reg := &registeredCallback[T]{cb: cb, policy: policy}
+	if policy.MaxConcurrency > 0 {
+		reg.sem = make(chan struct{}, policy.MaxConcurrency)
+	}
+	r.callbacks = append(r.callbacks, reg)
+}
+
+func (r *CallbackRegistry[T]) lockFor(key string) *sync.Mutex {
+	m, _ := r.keyMutexes.LoadOrStore(key, &sync.Mutex{})
+	return m.(*sync.Mutex)
+}
+
+// Invoke runs every registered callback in order against a path-scoped lock (key), recovering
+// any panic into a *CallbackError the same way commonChanCallback used to log via
+// debug.Stack(). A callback that exceeds its ExecutionPolicy.Timeout, or whose ctx is cancelled
+// while WaitForResult is set, aborts the walk and returns a *CallbackError; FireAndForget
+// callbacks are detached instead and never block the caller.
+func (r *CallbackRegistry[T]) Invoke(ctx context.Context, key string, event T) error {
+	lock := r.lockFor(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	for _, reg := range r.callbacks {
+		if err := r.runOne(ctx, reg, key, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *CallbackRegistry[T]) runOne(ctx context.Context, reg *registeredCallback[T], key string, event T) error {
+	runCtx := ctx
+	cancel := func() {}
+	if reg.policy.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, reg.policy.Timeout)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if reg.sem != nil {
+			reg.sem <- struct{}{}
+			defer func() { <-reg.sem }()
+		}
+		done <- r.safeCall(reg.cb, runCtx, event)
+	}()
+
+	if reg.policy.Cancellation == FireAndForget {
+		go func() {
+			defer cancel()
+			r.awaitFireAndForget(done, key)
+		}()
+		return nil
+	}
+	defer cancel()
+
+	select {
+	case err := <-done:
+		return err
+	case <-runCtx.Done():
+		logger.Errorf("callback-deadline-exceeded kind:%s key:%s error:%s", r.kind, key, runCtx.Err())
+		return &CallbackError{Kind: r.kind, Cause: runCtx.Err()}
+	}
+}
+
+func (r *CallbackRegistry[T]) awaitFireAndForget(done <-chan error, key string) {
+	if err := <-done; err != nil {
+		logger.Errorf("callback-fire-and-forget-failed kind:%s key:%s error:%s", r.kind, key, err)
+	}
+}
+
+func (r *CallbackRegistry[T]) safeCall(cb Callback[T], ctx context.Context, event T) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			stack := string(debug.Stack())
+			logger.Errorf("callback-panic kind:%s recovered:%v stack:%s", r.kind, rec, stack)
+			err = &CallbackError{Kind: r.kind, Cause: rec, Stack: stack}
+		}
+	}()
+	return cb(ctx, event)
+}
+
+// LegacyCallback is the historical args ...interface{} signature used throughout the proxy and
+// branch callback wiring. It is kept only so existing callers can be migrated incrementally.
+type LegacyCallback func(ctx context.Context, args ...interface{}) interface{}
+
+// AsTypedCallback adapts a LegacyCallback into a Callback[T]: it calls legacy with event placed at
+// args[argIndex] (lower indices are nil) and returns legacy's result as the error if it is one,
+// nil otherwise. It asserts nothing itself; a panic inside legacy propagates to the caller, which
+// CallbackRegistry.Invoke recovers into a *CallbackError. This is the thin interface{} shim mentioned for the transition period - new call sites should register
+// directly against a CallbackRegistry[T] instead of going through it.
+func AsTypedCallback[T any](legacy LegacyCallback, argIndex int) Callback[T] {
+	return func(ctx context.Context, event T) error {
+		args := make([]interface{}, argIndex+1)
+		args[argIndex] = event
+		result := legacy(ctx, args...)
+		if err, ok := result.(error); ok {
+			return err
+		}
+		return nil
+	}
+}
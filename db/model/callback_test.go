@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencord/voltha-protos/v3/go/voltha"
+)
+
+func TestCallbackRegistryInvokesInOrder(t *testing.T) {
+	registry := NewCallbackRegistry[*voltha.Device]("POST_UPDATE")
+	var seen []string
+	registry.Register(func(ctx context.Context, device *voltha.Device) error {
+		seen = append(seen, device.Id)
+		return nil
+	})
+
+	if err := registry.Invoke(context.Background(), "/devices/device-1", &voltha.Device{Id: "device-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seen) != 1 || seen[0] != "device-1" {
+		t.Fatalf("callback was not invoked with the expected event: %+v", seen)
+	}
+}
+
+func TestCallbackRegistryRecoversPanic(t *testing.T) {
+	registry := NewCallbackRegistry[*voltha.Device]("POST_REMOVE")
+	registry.Register(func(ctx context.Context, device *voltha.Device) error {
+		panic("boom")
+	})
+
+	err := registry.Invoke(context.Background(), "/devices/device-1", &voltha.Device{Id: "device-1"})
+	if err == nil {
+		t.Fatal("expected the panic to surface as an error")
+	}
+	if _, ok := err.(*CallbackError); !ok {
+		t.Fatalf("expected a *CallbackError, got %T", err)
+	}
+}
+
+func TestCallbackRegistryEnforcesTimeout(t *testing.T) {
+	registry := NewCallbackRegistry[*voltha.Device]("POST_UPDATE")
+	registry.RegisterWithPolicy(func(ctx context.Context, device *voltha.Device) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, ExecutionPolicy{Timeout: 10 * time.Millisecond, Cancellation: WaitForResult})
+
+	err := registry.Invoke(context.Background(), "/devices/device-1", &voltha.Device{Id: "device-1"})
+	if err == nil {
+		t.Fatal("expected the slow callback to trip its deadline")
+	}
+}
+
+func TestCallbackRegistryFireAndForgetDoesNotBlock(t *testing.T) {
+	registry := NewCallbackRegistry[*voltha.Device]("POST_UPDATE")
+	release := make(chan struct{})
+	registry.RegisterWithPolicy(func(ctx context.Context, device *voltha.Device) error {
+		<-release
+		return nil
+	}, ExecutionPolicy{Cancellation: FireAndForget})
+	defer close(release)
+
+	done := make(chan struct{})
+	go func() {
+		_ = registry.Invoke(context.Background(), "/devices/device-1", &voltha.Device{Id: "device-1"})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("FireAndForget callback blocked Invoke")
+	}
+}
+
+func TestAsTypedCallbackSurfacesLegacyError(t *testing.T) {
+	legacy := func(ctx context.Context, args ...interface{}) interface{} {
+		return nil
+	}
+	typed := AsTypedCallback[*voltha.Device](legacy, 1)
+	if err := typed(context.Background(), &voltha.Device{Id: "device-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	legacyErr := errors.New("legacy-failure")
+	failing := func(ctx context.Context, args ...interface{}) interface{} {
+		return legacyErr
+	}
+	typed = AsTypedCallback[*voltha.Device](failing, 1)
+	if err := typed(context.Background(), &voltha.Device{Id: "device-1"}); err != legacyErr {
+		t.Fatalf("expected the legacy error to be surfaced, got: %v", err)
+	}
+}
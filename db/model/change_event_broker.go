@@ -0,0 +1,186 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package model
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// ChangeEventType identifies the kind of mutation a ChangeEvent represents.
+type ChangeEventType int
+
+const (
+	ChangeEventAdd ChangeEventType = iota
+	ChangeEventUpdate
+	ChangeEventRemove
+)
+
+// ChangeEvent is the payload fanned out to ChangeEventBroker subscribers. It mirrors the
+// ModelMutation already available to the add/update/remove CallbackRegistry callbacks but in a
+// shape that can be handed to a gRPC stream.
+type ChangeEvent struct {
+	Type ChangeEventType
+	Path string
+	Data interface{}
+}
+
+// ChangeEventFilter restricts a subscription to a subtree of the model, e.g. "/devices".
+type ChangeEventFilter struct {
+	PathPrefix string
+}
+
+func (f ChangeEventFilter) matches(path string) bool {
+	return f.PathPrefix == "" || strings.HasPrefix(path, f.PathPrefix)
+}
+
+// subscription is one outstanding Subscribe() call. events is bounded so a slow subscriber
+// cannot stall model writes; once full, the oldest buffered event is dropped and overflowCount
+// is incremented for metrics.
+type subscription struct {
+	filter        ChangeEventFilter
+	events        chan ChangeEvent
+	overflowCount uint64
+	mu            sync.Mutex
+}
+
+func (s *subscription) publish(event ChangeEvent) {
+	if !s.filter.matches(event.Path) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case s.events <- event:
+	default:
+		// Ring is full: drop the oldest buffered event to make room for the new one rather
+		// than blocking the caller (which would stall the proxy callback that fed us).
+		select {
+		case <-s.events:
+		default:
+		}
+		s.overflowCount++
+		select {
+		case s.events <- event:
+		default:
+		}
+	}
+}
+
+// ModelMutation is what CallbackRegistry[ModelMutation] invokes ChangeEventBroker's callbacks
+// with: the path that changed and the data now (or, for a remove, formerly) stored there.
+type ModelMutation struct {
+	Path string
+	Data interface{}
+}
+
+// ChangeEventBroker fans model-mutation callbacks out to any number of gRPC subscribers, each
+// with its own bounded ring buffer, and replays a snapshot of the current subtree to late
+// subscribers - via an injected snapshot func, since this package has no tree-walking Get of its
+// own - before switching them to live tail.
+type ChangeEventBroker struct {
+	ringSize    int
+	snapshot    func(pathPrefix string) []ChangeEvent
+	mu          sync.Mutex
+	subscribers map[*subscription]struct{}
+}
+
+// NewChangeEventBroker registers the broker's onAdd/onUpdate/onRemove as callbacks on the
+// given per-event-kind registries and returns the broker so RPC handlers can call Subscribe.
+// ringSize bounds the per-subscriber replay/overflow buffer. snapshot, if non-nil, is called by
+// Subscribe to synthesize a resync burst of ChangeEventAdd events for a newly (re)connected
+// subscriber; a nil snapshot just skips the resync and starts the subscriber on live events only.
+func NewChangeEventBroker(
+	addRegistry, updateRegistry, removeRegistry *CallbackRegistry[ModelMutation],
+	ringSize int,
+	snapshot func(pathPrefix string) []ChangeEvent,
+) *ChangeEventBroker {
+	b := &ChangeEventBroker{
+		ringSize:    ringSize,
+		snapshot:    snapshot,
+		subscribers: make(map[*subscription]struct{}),
+	}
+	addRegistry.Register(b.onAdd)
+	updateRegistry.Register(b.onUpdate)
+	removeRegistry.Register(b.onRemove)
+	return b
+}
+
+func (b *ChangeEventBroker) onAdd(ctx context.Context, event ModelMutation) error {
+	return b.publish(ChangeEventAdd, event)
+}
+
+func (b *ChangeEventBroker) onUpdate(ctx context.Context, event ModelMutation) error {
+	return b.publish(ChangeEventUpdate, event)
+}
+
+func (b *ChangeEventBroker) onRemove(ctx context.Context, event ModelMutation) error {
+	return b.publish(ChangeEventRemove, event)
+}
+
+func (b *ChangeEventBroker) publish(t ChangeEventType, mutation ModelMutation) error {
+	event := ChangeEvent{Type: t, Path: mutation.Path, Data: mutation.Data}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		sub.publish(event)
+	}
+	return nil
+}
+
+// Subscribe registers a new subscriber matching filter, synthesizes "add" events for every node
+// already present under filter.PathPrefix (so a reconnecting client gets a consistent snapshot),
+// and returns a channel that then tails live events until ctx is cancelled.
+func (b *ChangeEventBroker) Subscribe(ctx context.Context, filter ChangeEventFilter) <-chan ChangeEvent {
+	sub := &subscription{
+		filter: filter,
+		events: make(chan ChangeEvent, b.ringSize),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	for _, resync := range b.resync(filter) {
+		sub.publish(resync)
+	}
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subscribers, sub)
+		b.mu.Unlock()
+		close(sub.events)
+	}()
+
+	return sub.events
+}
+
+// resync delegates to the broker's injected snapshot func to synthesize a burst of
+// ChangeEventAdd events for filter.PathPrefix, giving a freshly (re)connected subscriber a
+// consistent starting point before it starts receiving live deltas. A broker with no snapshot
+// func configured just skips resync.
+func (b *ChangeEventBroker) resync(filter ChangeEventFilter) []ChangeEvent {
+	if b.snapshot == nil {
+		return nil
+	}
+	path := filter.PathPrefix
+	if path == "" {
+		path = "/"
+	}
+	return b.snapshot(path)
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestChangeEventBrokerPublishesAddUpdateRemove(t *testing.T) {
+	addRegistry := NewCallbackRegistry[ModelMutation]("POST_ADD")
+	updateRegistry := NewCallbackRegistry[ModelMutation]("POST_UPDATE")
+	removeRegistry := NewCallbackRegistry[ModelMutation]("POST_REMOVE")
+	broker := NewChangeEventBroker(addRegistry, updateRegistry, removeRegistry, 10, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := broker.Subscribe(ctx, ChangeEventFilter{})
+
+	if err := addRegistry.Invoke(context.Background(), "/devices/device-1", ModelMutation{Path: "/devices/device-1", Data: "added"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := updateRegistry.Invoke(context.Background(), "/devices/device-1", ModelMutation{Path: "/devices/device-1", Data: "updated"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := removeRegistry.Invoke(context.Background(), "/devices/device-1", ModelMutation{Path: "/devices/device-1", Data: "removed"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantTypes := []ChangeEventType{ChangeEventAdd, ChangeEventUpdate, ChangeEventRemove}
+	for i, want := range wantTypes {
+		select {
+		case event := <-ch:
+			if event.Type != want {
+				t.Fatalf("event %d: got type %v, want %v", i, event.Type, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d: timed out waiting for ChangeEvent", i)
+		}
+	}
+}
+
+func TestChangeEventBrokerFilterScopesToPathPrefix(t *testing.T) {
+	addRegistry := NewCallbackRegistry[ModelMutation]("POST_ADD")
+	updateRegistry := NewCallbackRegistry[ModelMutation]("POST_UPDATE")
+	removeRegistry := NewCallbackRegistry[ModelMutation]("POST_REMOVE")
+	broker := NewChangeEventBroker(addRegistry, updateRegistry, removeRegistry, 10, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := broker.Subscribe(ctx, ChangeEventFilter{PathPrefix: "/devices"})
+
+	if err := addRegistry.Invoke(context.Background(), "/logical_devices/ld-1", ModelMutation{Path: "/logical_devices/ld-1", Data: "ld"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := addRegistry.Invoke(context.Background(), "/devices/device-1", ModelMutation{Path: "/devices/device-1", Data: "d"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case event := <-ch:
+		if event.Path != "/devices/device-1" {
+			t.Fatalf("expected the out-of-scope /logical_devices event to be filtered out, got: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the in-scope ChangeEvent")
+	}
+}
+
+func TestChangeEventBrokerResyncsFromSnapshot(t *testing.T) {
+	addRegistry := NewCallbackRegistry[ModelMutation]("POST_ADD")
+	updateRegistry := NewCallbackRegistry[ModelMutation]("POST_UPDATE")
+	removeRegistry := NewCallbackRegistry[ModelMutation]("POST_REMOVE")
+	snapshot := func(pathPrefix string) []ChangeEvent {
+		return []ChangeEvent{{Type: ChangeEventAdd, Path: pathPrefix + "/device-1", Data: "resynced"}}
+	}
+	broker := NewChangeEventBroker(addRegistry, updateRegistry, removeRegistry, 10, snapshot)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := broker.Subscribe(ctx, ChangeEventFilter{PathPrefix: "/devices"})
+
+	select {
+	case event := <-ch:
+		if event.Path != "/devices/device-1" || event.Data != "resynced" {
+			t.Fatalf("expected the resync snapshot event, got: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the resync ChangeEvent")
+	}
+}
+
+func TestChangeEventBrokerUnsubscribesOnContextCancel(t *testing.T) {
+	addRegistry := NewCallbackRegistry[ModelMutation]("POST_ADD")
+	updateRegistry := NewCallbackRegistry[ModelMutation]("POST_UPDATE")
+	removeRegistry := NewCallbackRegistry[ModelMutation]("POST_REMOVE")
+	broker := NewChangeEventBroker(addRegistry, updateRegistry, removeRegistry, 10, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := broker.Subscribe(ctx, ChangeEventFilter{})
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatal("expected the subscription channel to close once ctx is cancelled")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the subscription channel to close")
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package model
+
+import (
+	"fmt"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
+)
+
+// RevisionStore pages a Branch's Revisions in and out of a backing store keyed by hash, so a
+// long-running core's memory use tracks the set of revisions actually reachable from a branch's
+// Latest/Origin pointers rather than every revision it has ever produced. Implementations must be
+// safe for concurrent use; a Branch may call into one from multiple goroutines.
+type RevisionStore interface {
+	// SaveRevision persists rev under hash, overwriting any previous value.
+	SaveRevision(hash string, rev Revision) error
+	// LoadRevision retrieves the revision saved under hash. found is false, with a nil error, if
+	// no such hash has been saved.
+	LoadRevision(hash string) (rev Revision, found bool, err error)
+	// DeleteRevision removes hash from the store; deleting a hash that was never saved is not an
+	// error.
+	DeleteRevision(hash string) error
+}
+
+// RevisionCodec converts between a Revision and the bytes RevisionStore persists. Revision's
+// concrete type is chosen by the node/root package this one is paired with, so the codec -
+// rather than this package - owns the (de)serialization format.
+type RevisionCodec interface {
+	Encode(rev Revision) ([]byte, error)
+	Decode(data []byte) (Revision, error)
+}
+
+// kvRevisionStore is the KV-backed RevisionStore NewKVRevisionStore returns: it keys revisions under
+// prefix+"/"+hash in whatever kvstore.Client the core is already configured with (see
+// rw_core/config.NewKVClient), so no second storage system is introduced just for this.
+type kvRevisionStore struct {
+	client kvstore.Client
+	prefix string
+	codec  RevisionCodec
+}
+
+// NewKVRevisionStore returns a RevisionStore backed by client, namespacing every key under
+// prefix (typically the core's existing KVStoreDataPrefix plus "/revisions").
+func NewKVRevisionStore(client kvstore.Client, prefix string, codec RevisionCodec) RevisionStore {
+	return &kvRevisionStore{client: client, prefix: prefix, codec: codec}
+}
+
+func (s *kvRevisionStore) key(hash string) string {
+	return s.prefix + "/" + hash
+}
+
+func (s *kvRevisionStore) SaveRevision(hash string, rev Revision) error {
+	data, err := s.codec.Encode(rev)
+	if err != nil {
+		return fmt.Errorf("revision-store-encode-%s: %w", hash, err)
+	}
+	return s.client.Put(s.key(hash), data)
+}
+
+func (s *kvRevisionStore) LoadRevision(hash string) (Revision, bool, error) {
+	pair, err := s.client.Get(s.key(hash))
+	if err != nil {
+		return nil, false, fmt.Errorf("revision-store-get-%s: %w", hash, err)
+	}
+	if pair == nil {
+		return nil, false, nil
+	}
+	data, ok := pair.Value.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("revision-store-get-%s: unexpected value type %T", hash, pair.Value)
+	}
+	rev, err := s.codec.Decode(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("revision-store-decode-%s: %w", hash, err)
+	}
+	return rev, true, nil
+}
+
+func (s *kvRevisionStore) DeleteRevision(hash string) error {
+	return s.client.Delete(s.key(hash))
+}
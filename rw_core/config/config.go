@@ -19,8 +19,11 @@ package config
 import (
 	"flag"
 	"fmt"
-	"github.com/opencord/voltha-lib-go/v3/pkg/adapters/common"
+	"os"
+	"strings"
 	"time"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/adapters/common"
 )
 
 // RW Core service default constants
@@ -55,6 +58,8 @@ const (
 	defaultLiveProbeInterval         = 60 * time.Second
 	defaultNotLiveProbeInterval      = 5 * time.Second // Probe more frequently when not alive
 	defaultProbeAddress              = ":8080"
+	defaultTraceEnabled              = false
+	defaultTraceAgentAddress         = "127.0.0.1:4317"
 )
 
 type stringValue string
@@ -75,37 +80,49 @@ func (i *stringValue) String() string {
 	return string(*i)
 }
 
-// RWCoreFlags represents the set of configurations used by the read-write core service
+// RWCoreFlags represents the set of configurations used by the read-write core service.
+//
+// Each field carries two struct tags consumed by Loader (config_loader.go), not by the flag
+// package: `env` names the VOLTHA_-prefixed environment variable that overrides it, and `reload`
+// says whether Watch is allowed to apply a changed value at runtime (false means changing it
+// requires a restart, e.g. because it is only read once when a listener or client is created).
 type RWCoreFlags struct {
 	// Command line parameters
-	RWCoreEndpoint            string
-	GrpcAddress               string
-	KafkaAdapterAddress       stringValue
-	KafkaClusterAddress       string
-	KVStoreType               string
-	KVStoreTimeout            int // in seconds
-	KVStoreAddress            string
-	KVTxnKeyDelTime           int
-	KVStoreDataPrefix         string
-	CoreTopic                 string
-	LogLevel                  string
-	Banner                    bool
-	DisplayVersionOnly        bool
-	RWCoreKey                 string
-	RWCoreCert                string
-	RWCoreCA                  string
-	AffinityRouterTopic       string
-	InCompetingMode           bool
-	LongRunningRequestTimeout time.Duration
-	DefaultRequestTimeout     time.Duration
-	DefaultCoreTimeout        time.Duration
-	CoreBindingKey            string
-	CorePairTopic             string
-	MaxConnectionRetries      int
-	ConnectionRetryInterval   time.Duration
-	LiveProbeInterval         time.Duration
-	NotLiveProbeInterval      time.Duration
-	ProbeAddress              string
+	RWCoreEndpoint            string        `env:"VOLTHA_RWCORE_ENDPOINT" reload:"false"`
+	GrpcAddress               string        `env:"VOLTHA_GRPC_ADDRESS" reload:"false"`
+	KafkaAdapterAddress       stringValue   `env:"VOLTHA_KAFKA_ADAPTER_ADDRESS" reload:"false"`
+	KafkaClusterAddress       string        `env:"VOLTHA_KAFKA_CLUSTER_ADDRESS" reload:"false"`
+	KVStoreType               string        `env:"VOLTHA_KV_STORE_TYPE" reload:"false"`
+	KVStoreTimeout            int           `env:"VOLTHA_KV_STORE_TIMEOUT" reload:"false"` // in seconds
+	KVStoreAddress            string        `env:"VOLTHA_KV_STORE_ADDRESS" reload:"false"`
+	KVTxnKeyDelTime           int           `env:"VOLTHA_KV_TXN_DELETE_TIME" reload:"false"`
+	KVStoreDataPrefix         string        `env:"VOLTHA_KV_STORE_DATA_PREFIX" reload:"false"`
+	CoreTopic                 string        `env:"VOLTHA_CORE_TOPIC" reload:"false"`
+	LogLevel                  string        `env:"VOLTHA_LOG_LEVEL" reload:"true"`
+	Banner                    bool          `env:"VOLTHA_BANNER" reload:"false"`
+	DisplayVersionOnly        bool          `env:"VOLTHA_DISPLAY_VERSION_ONLY" reload:"false"`
+	RWCoreKey                 string        `env:"VOLTHA_RWCORE_KEY" reload:"false"`
+	RWCoreCert                string        `env:"VOLTHA_RWCORE_CERT" reload:"false"`
+	RWCoreCA                  string        `env:"VOLTHA_RWCORE_CA" reload:"false"`
+	AffinityRouterTopic       string        `env:"VOLTHA_AFFINITY_ROUTER_TOPIC" reload:"false"`
+	InCompetingMode           bool          `env:"VOLTHA_IN_COMPETING_MODE" reload:"false"`
+	LongRunningRequestTimeout time.Duration `env:"VOLTHA_TIMEOUT_LONG_REQUEST" reload:"true"`
+	DefaultRequestTimeout     time.Duration `env:"VOLTHA_TIMEOUT_REQUEST" reload:"true"`
+	DefaultCoreTimeout        time.Duration `env:"VOLTHA_CORE_TIMEOUT" reload:"true"`
+	CoreBindingKey            string        `env:"VOLTHA_CORE_BINDING_KEY" reload:"false"`
+	CorePairTopic             string        `env:"VOLTHA_CORE_PAIR_TOPIC" reload:"false"`
+	MaxConnectionRetries      int           `env:"VOLTHA_MAX_CONNECTION_RETRIES" reload:"false"`
+	ConnectionRetryInterval   time.Duration `env:"VOLTHA_CONNECTION_RETRY_INTERVAL" reload:"false"`
+	LiveProbeInterval         time.Duration `env:"VOLTHA_LIVE_PROBE_INTERVAL" reload:"true"`
+	NotLiveProbeInterval      time.Duration `env:"VOLTHA_NOT_LIVE_PROBE_INTERVAL" reload:"true"`
+	ProbeAddress              string        `env:"VOLTHA_PROBE_ADDRESS" reload:"false"`
+	TraceEnabled              bool          `env:"VOLTHA_TRACE_ENABLED" reload:"true"`
+	TraceAgentAddress         string        `env:"VOLTHA_TRACE_AGENT_ADDRESS" reload:"false"`
+
+	// ConfigFile is the path to an optional YAML/JSON file (set via --config) that Loader reads
+	// before environment variables and CLI flags are applied; it is not itself overridable by
+	// the file or env layers.
+	ConfigFile string
 }
 
 // NewRWCoreFlags returns a new RWCore config
@@ -139,92 +156,127 @@ func NewRWCoreFlags() *RWCoreFlags {
 		LiveProbeInterval:         defaultLiveProbeInterval,
 		NotLiveProbeInterval:      defaultNotLiveProbeInterval,
 		ProbeAddress:              defaultProbeAddress,
+		TraceEnabled:              defaultTraceEnabled,
+		TraceAgentAddress:         defaultTraceAgentAddress,
 	}
 	return &rwCoreFlag
 }
 
-// ParseCommandArguments parses the arguments when running read-write core service
+// ParseCommandArguments parses the arguments when running read-write core service. Values are
+// resolved in precedence order defaults -> --config file -> VOLTHA_-prefixed environment
+// variables -> CLI flags: cf already holds the NewRWCoreFlags defaults, a first scan of os.Args
+// picks up --config (wherever it appears among the other flags), Loader.Load layers the file and
+// environment on top of that, and the real flag.Parse pass registers every flag with the
+// now-layered value as its default so an explicit flag still wins but an unset one keeps whatever
+// the file/env layer produced.
 func (cf *RWCoreFlags) ParseCommandArguments() {
+	help := fmt.Sprintf("Path to a YAML or JSON configuration file")
+	flag.StringVar(&(cf.ConfigFile), "config", "", help)
+	args := os.Args[1:]
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == "config" && i+1 < len(args) {
+			cf.ConfigFile = args[i+1]
+		} else if strings.HasPrefix(name, "config=") {
+			cf.ConfigFile = strings.TrimPrefix(name, "config=")
+		}
+	}
 
-	help := fmt.Sprintf("RW core endpoint address")
-	flag.StringVar(&(cf.RWCoreEndpoint), "vcore-endpoint", defaultRWCoreEndpoint, help)
+	if err := NewLoader(cf).Load(cf.ConfigFile); err != nil {
+		fmt.Printf("failed-to-load-config-file-or-environment: %s\n", err)
+	}
+
+	help = fmt.Sprintf("RW core endpoint address")
+	flag.StringVar(&(cf.RWCoreEndpoint), "vcore-endpoint", cf.RWCoreEndpoint, help)
 
 	help = fmt.Sprintf("GRPC server - address")
-	flag.StringVar(&(cf.GrpcAddress), "grpc_address", defaultGrpcAddress, help)
+	flag.StringVar(&(cf.GrpcAddress), "grpc_address", cf.GrpcAddress, help)
 
 	help = fmt.Sprintf("Kafka - Adapter messaging address")
 	flag.Var(&(cf.KafkaAdapterAddress), "kafka_adapter_address", help)
 
 	help = fmt.Sprintf("Kafka - Cluster messaging address")
-	flag.StringVar(&(cf.KafkaClusterAddress), "kafka_cluster_address", defaultKafkaClusterAddress, help)
+	flag.StringVar(&(cf.KafkaClusterAddress), "kafka_cluster_address", cf.KafkaClusterAddress, help)
 
 	help = fmt.Sprintf("RW Core topic")
-	flag.StringVar(&(cf.CoreTopic), "rw_core_topic", defaultCoreTopic, help)
+	flag.StringVar(&(cf.CoreTopic), "rw_core_topic", cf.CoreTopic, help)
 
 	help = fmt.Sprintf("Affinity Router topic")
-	flag.StringVar(&(cf.AffinityRouterTopic), "affinity_router_topic", defaultAffinityRouterTopic, help)
+	flag.StringVar(&(cf.AffinityRouterTopic), "affinity_router_topic", cf.AffinityRouterTopic, help)
 
 	help = fmt.Sprintf("In competing Mode - two cores competing to handle a transaction ")
-	flag.BoolVar(&cf.InCompetingMode, "in_competing_mode", defaultInCompetingMode, help)
+	flag.BoolVar(&cf.InCompetingMode, "in_competing_mode", cf.InCompetingMode, help)
 
 	help = fmt.Sprintf("KV store type")
-	flag.StringVar(&(cf.KVStoreType), "kv_store_type", defaultKVStoreType, help)
+	flag.StringVar(&(cf.KVStoreType), "kv_store_type", cf.KVStoreType, help)
 
 	help = fmt.Sprintf("The default timeout when making a kv store request")
-	flag.IntVar(&(cf.KVStoreTimeout), "kv_store_request_timeout", defaultKVStoreTimeout, help)
+	flag.IntVar(&(cf.KVStoreTimeout), "kv_store_request_timeout", cf.KVStoreTimeout, help)
 
 	help = fmt.Sprintf("KV store address")
-	flag.StringVar(&(cf.KVStoreAddress), "kv_store_address", defaultKVStoreAddress, help)
+	flag.StringVar(&(cf.KVStoreAddress), "kv_store_address", cf.KVStoreAddress, help)
 
 	help = fmt.Sprintf("The time to wait before deleting a completed transaction key")
-	flag.IntVar(&(cf.KVTxnKeyDelTime), "kv_txn_delete_time", defaultKVTxnKeyDelTime, help)
+	flag.IntVar(&(cf.KVTxnKeyDelTime), "kv_txn_delete_time", cf.KVTxnKeyDelTime, help)
 
 	help = fmt.Sprintf("KV store data prefix")
-	flag.StringVar(&(cf.KVStoreDataPrefix), "kv_store_data_prefix", defaultKVStoreDataPrefix, help)
+	flag.StringVar(&(cf.KVStoreDataPrefix), "kv_store_data_prefix", cf.KVStoreDataPrefix, help)
 
 	help = fmt.Sprintf("Log level")
-	flag.StringVar(&(cf.LogLevel), "log_level", defaultLogLevel, help)
+	flag.StringVar(&(cf.LogLevel), "log_level", cf.LogLevel, help)
 
 	help = fmt.Sprintf("Timeout for long running request")
 	// TODO:  Change this code once all the params and helm charts have been changed to use the different type
-	var temp int64
-	flag.Int64Var(&temp, "timeout_long_request", defaultLongRunningRequestTimeout.Milliseconds(), help)
-	cf.LongRunningRequestTimeout = time.Duration(temp) * time.Millisecond
+	var longRunningRequestTimeoutMs = cf.LongRunningRequestTimeout.Milliseconds()
+	flag.Int64Var(&longRunningRequestTimeoutMs, "timeout_long_request", longRunningRequestTimeoutMs, help)
 
 	help = fmt.Sprintf("Default timeout for regular request")
-	flag.Int64Var(&temp, "timeout_request", defaultDefaultRequestTimeout.Milliseconds(), help)
-	cf.DefaultRequestTimeout = time.Duration(temp) * time.Millisecond
+	var defaultRequestTimeoutMs = cf.DefaultRequestTimeout.Milliseconds()
+	flag.Int64Var(&defaultRequestTimeoutMs, "timeout_request", defaultRequestTimeoutMs, help)
 
 	help = fmt.Sprintf("Default Core timeout")
-	flag.Int64Var(&temp, "core_timeout", defaultCoreTimeout.Milliseconds(), help)
-	cf.DefaultCoreTimeout = time.Duration(temp) * time.Millisecond
+	var defaultCoreTimeoutMs = cf.DefaultCoreTimeout.Milliseconds()
+	flag.Int64Var(&defaultCoreTimeoutMs, "core_timeout", defaultCoreTimeoutMs, help)
 
 	help = fmt.Sprintf("Show startup banner log lines")
-	flag.BoolVar(&cf.Banner, "banner", defaultBanner, help)
+	flag.BoolVar(&cf.Banner, "banner", cf.Banner, help)
 
 	help = fmt.Sprintf("Show version information and exit")
-	flag.BoolVar(&cf.DisplayVersionOnly, "version", defaultDisplayVersionOnly, help)
+	flag.BoolVar(&cf.DisplayVersionOnly, "version", cf.DisplayVersionOnly, help)
 
 	help = fmt.Sprintf("The name of the meta-key whose value is the rw-core group to which the ofagent is bound")
-	flag.StringVar(&(cf.CoreBindingKey), "core_binding_key", defaultCoreBindingKey, help)
+	flag.StringVar(&(cf.CoreBindingKey), "core_binding_key", cf.CoreBindingKey, help)
 
 	help = fmt.Sprintf("Core pairing group topic")
-	flag.StringVar(&cf.CorePairTopic, "core_pair_topic", defaultCorePairTopic, help)
+	flag.StringVar(&cf.CorePairTopic, "core_pair_topic", cf.CorePairTopic, help)
 
 	help = fmt.Sprintf("The number of retries to connect to a dependent component")
-	flag.IntVar(&(cf.MaxConnectionRetries), "max_connection_retries", defaultMaxConnectionRetries, help)
+	flag.IntVar(&(cf.MaxConnectionRetries), "max_connection_retries", cf.MaxConnectionRetries, help)
 
 	help = fmt.Sprintf("The number of seconds between each connection retry attempt")
-	flag.DurationVar(&(cf.ConnectionRetryInterval), "connection_retry_interval", defaultConnectionRetryInterval, help)
+	flag.DurationVar(&(cf.ConnectionRetryInterval), "connection_retry_interval", cf.ConnectionRetryInterval, help)
 
 	help = fmt.Sprintf("The number of seconds between liveness probes while in a live state")
-	flag.DurationVar(&(cf.LiveProbeInterval), "live_probe_interval", defaultLiveProbeInterval, help)
+	flag.DurationVar(&(cf.LiveProbeInterval), "live_probe_interval", cf.LiveProbeInterval, help)
 
 	help = fmt.Sprintf("The number of seconds between liveness probes while in a not live state")
-	flag.DurationVar(&(cf.NotLiveProbeInterval), "not_live_probe_interval", defaultNotLiveProbeInterval, help)
+	flag.DurationVar(&(cf.NotLiveProbeInterval), "not_live_probe_interval", cf.NotLiveProbeInterval, help)
 
 	help = fmt.Sprintf("The address on which to listen to answer liveness and readiness probe queries over HTTP.")
-	flag.StringVar(&(cf.ProbeAddress), "probe_address", defaultProbeAddress, help)
+	flag.StringVar(&(cf.ProbeAddress), "probe_address", cf.ProbeAddress, help)
+
+	help = fmt.Sprintf("Enable exporting OpenTelemetry traces to trace_agent_address")
+	flag.BoolVar(&(cf.TraceEnabled), "trace_enabled", cf.TraceEnabled, help)
+
+	help = fmt.Sprintf("The OTLP exporter address the RW core sends traces to when trace_enabled is set")
+	flag.StringVar(&(cf.TraceAgentAddress), "trace_agent_address", cf.TraceAgentAddress, help)
 
 	flag.Parse()
+
+	cf.LongRunningRequestTimeout = time.Duration(longRunningRequestTimeoutMs) * time.Millisecond
+	cf.DefaultRequestTimeout = time.Duration(defaultRequestTimeoutMs) * time.Millisecond
+	cf.DefaultCoreTimeout = time.Duration(defaultCoreTimeoutMs) * time.Millisecond
 }
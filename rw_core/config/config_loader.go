@@ -0,0 +1,196 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Loader layers a config file and the environment on top of a RWCoreFlags that already holds its
+// NewRWCoreFlags defaults. It works off the `env` and `reload` struct tags on RWCoreFlags rather
+// than a hand-maintained list of fields, so a new flag only needs a tag to participate in both
+// layering and hot reload. Watch does not go through Loader: it re-reads only the config file (or
+// the KV config key), not the environment, and applies the result through the same tags.
+type Loader struct {
+	cf *RWCoreFlags
+}
+
+// NewLoader returns a Loader that layers file/environment values onto cf in place.
+func NewLoader(cf *RWCoreFlags) *Loader {
+	return &Loader{cf: cf}
+}
+
+// Load reads configFile (if non-empty; a missing file is not an error) and merges it onto the
+// Loader's RWCoreFlags, then merges every VOLTHA_-prefixed environment variable named by an `env`
+// tag on top of that. Flags are applied afterwards by ParseCommandArguments, so this only ever
+// sets the defaults flag.Parse starts from.
+func (l *Loader) Load(configFile string) error {
+	if configFile != "" {
+		values, err := readConfigFile(configFile)
+		if err != nil {
+			return fmt.Errorf("config-file %s: %w", configFile, err)
+		}
+		if err := applyValues(l.cf, values, false); err != nil {
+			return fmt.Errorf("config-file %s: %w", configFile, err)
+		}
+	}
+
+	env := map[string]string{}
+	forEachField(l.cf, func(_ string, envVar string, _ bool) {
+		if v, ok := os.LookupEnv(envVar); ok {
+			env[envVar] = v
+		}
+	})
+	if err := applyEnv(l.cf, env, false); err != nil {
+		return fmt.Errorf("environment: %w", err)
+	}
+	return nil
+}
+
+// readConfigFile loads a YAML or JSON file (JSON is valid YAML, so both decode the same way) into
+// a map keyed by RWCoreFlags field name. A missing file is treated as "nothing to layer" rather
+// than an error, since --config is optional.
+func readConfigFile(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseConfigBytes(data)
+}
+
+// parseConfigBytes is readConfigFile's decoder, split out so config_watch.go's KV polling path -
+// which already has the bytes in hand from a Get - can reuse it without round-tripping a file.
+func parseConfigBytes(data []byte) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	// Keys are matched case-insensitively against field names so "grpcAddress" (camelCase, the
+	// usual YAML convention) and "GrpcAddress" (the Go field name) both work.
+	normalized := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		normalized[strings.ToLower(k)] = v
+	}
+	return normalized, nil
+}
+
+// forEachField walks the `env`/`reload` tags on cf's fields, invoking fn with the Go field name,
+// its env var name, and whether it is reload-safe. Fields without an `env` tag (ConfigFile) are
+// skipped.
+func forEachField(cf *RWCoreFlags, fn func(fieldName, envVar string, hotReloadable bool)) {
+	t := reflect.TypeOf(*cf)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		envVar, ok := field.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		fn(field.Name, envVar, field.Tag.Get("reload") == "true")
+	}
+}
+
+// applyValues sets each RWCoreFlags field named in values (matched case-insensitively) to the
+// corresponding parsed value. hotOnly restricts the set to reload-safe fields, used by Watch;
+// Load passes false to apply every field since nothing is running yet.
+func applyValues(cf *RWCoreFlags, values map[string]interface{}, hotOnly bool) error {
+	if len(values) == 0 {
+		return nil
+	}
+	v := reflect.ValueOf(cf).Elem()
+	var firstErr error
+	forEachField(cf, func(fieldName, _ string, hotReloadable bool) {
+		if hotOnly && !hotReloadable {
+			return
+		}
+		raw, ok := values[strings.ToLower(fieldName)]
+		if !ok {
+			return
+		}
+		if err := setField(v.FieldByName(fieldName), fmt.Sprintf("%v", raw)); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", fieldName, err)
+		}
+	})
+	return firstErr
+}
+
+// applyEnv is applyValues' counterpart for a map of already-looked-up environment variables,
+// keyed by env var name rather than field name.
+func applyEnv(cf *RWCoreFlags, env map[string]string, hotOnly bool) error {
+	if len(env) == 0 {
+		return nil
+	}
+	v := reflect.ValueOf(cf).Elem()
+	var firstErr error
+	forEachField(cf, func(fieldName, envVar string, hotReloadable bool) {
+		if hotOnly && !hotReloadable {
+			return
+		}
+		raw, ok := env[envVar]
+		if !ok {
+			return
+		}
+		if err := setField(v.FieldByName(fieldName), raw); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s (%s): %w", fieldName, envVar, err)
+		}
+	})
+	return firstErr
+}
+
+// setField parses raw into field's type and assigns it. Like the rest of this package's reflection
+// (forEachField, applyValues, applyEnv, applyReload), it is confined to the low-frequency
+// config-load/reload path rather than any per-request code, so the cost is negligible and the
+// alternative (one case per field) would just be noise.
+func setField(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		if field.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+)
+
+// kvWatchPollInterval bounds how often Watch re-reads KVStoreAddress when no config file is set;
+// the kvstore.Client abstraction that etcd/consul/redis share (see kvstore_factory.go) doesn't
+// expose a watch primitive common to all three backends, so polling is the portable option.
+const kvWatchPollInterval = 5 * time.Second
+
+// ConfigDiff describes one field that changed between two successive reads of the config
+// file or KV layer. Err is set instead of Old/New being applied when the field is not
+// reload-safe (its `reload:"false"` tag) or the new value failed to parse; in that case cf is
+// left unchanged and the caller decides whether to log, alert, or restart.
+type ConfigDiff struct {
+	Field string
+	Old   string
+	New   string
+	Err   error
+}
+
+// Watch starts watching for configuration changes - the --config file via fsnotify if one was
+// given, otherwise KVStoreAddress via polling if one is set - and returns a channel of ConfigDiff,
+// one per changed field, that subsystems (gRPC server, KV client, probe endpoint) can range over
+// to pick up new timeouts, log levels, or probe intervals without a restart. Watch returns a nil
+// channel and no error when neither source is configured; there is nothing to watch. The returned
+// channel is closed when ctx is done.
+func (cf *RWCoreFlags) Watch(ctx context.Context) (<-chan ConfigDiff, error) {
+	diffs := make(chan ConfigDiff, 16)
+
+	switch {
+	case cf.ConfigFile != "":
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return nil, fmt.Errorf("config-watch: %w", err)
+		}
+		if err := watcher.Add(cf.ConfigFile); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("config-watch: %w", err)
+		}
+		go cf.watchFile(ctx, watcher, diffs)
+	case cf.KVStoreAddress != "":
+		go cf.watchKV(ctx, diffs)
+	default:
+		return nil, nil
+	}
+
+	return diffs, nil
+}
+
+func (cf *RWCoreFlags) watchFile(ctx context.Context, watcher *fsnotify.Watcher, diffs chan<- ConfigDiff) {
+	defer watcher.Close()
+	defer close(diffs)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			values, err := readConfigFile(cf.ConfigFile)
+			if err != nil {
+				log.Warnw("config-watch-read-failed", log.Fields{"file": cf.ConfigFile, "error": err})
+				continue
+			}
+			cf.applyReload(values, diffs)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warnw("config-watch-error", log.Fields{"file": cf.ConfigFile, "error": err})
+		}
+	}
+}
+
+func (cf *RWCoreFlags) watchKV(ctx context.Context, diffs chan<- ConfigDiff) {
+	defer close(diffs)
+	ticker := time.NewTicker(kvWatchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			client, err := NewKVClient(cf)
+			if err != nil {
+				log.Warnw("config-watch-kv-client-failed", log.Fields{"error": err})
+				continue
+			}
+			pair, err := client.Get(cf.KVStoreDataPrefix + "/config")
+			if err != nil || pair == nil {
+				continue
+			}
+			data, ok := pair.Value.([]byte)
+			if !ok {
+				log.Warnw("config-watch-kv-unexpected-value-type", log.Fields{"type": fmt.Sprintf("%T", pair.Value)})
+				continue
+			}
+			values, err := parseConfigBytes(data)
+			if err != nil {
+				log.Warnw("config-watch-kv-parse-failed", log.Fields{"error": err})
+				continue
+			}
+			cf.applyReload(values, diffs)
+		}
+	}
+}
+
+// applyReload computes, for every reload-safe field named in values, a ConfigDiff against cf's
+// current value, applies it if it parses and differs, and sends the diff either way - fields
+// whose value didn't change are not reported, but a field present in values that is NOT
+// reload-safe still gets a ConfigDiff with Err set instead of being silently skipped, per the
+// validation-hooks requirement: a hot change to e.g. GrpcAddress must surface as an error, not be
+// ignored or applied underneath a running listener.
+func (cf *RWCoreFlags) applyReload(values map[string]interface{}, diffs chan<- ConfigDiff) {
+	v := reflect.ValueOf(cf).Elem()
+	forEachField(cf, func(fieldName, envVar string, hotReloadable bool) {
+		raw, ok := values[strings.ToLower(fieldName)]
+		if !ok {
+			return
+		}
+		field := v.FieldByName(fieldName)
+		old := fmt.Sprintf("%v", field.Interface())
+		newRaw := fmt.Sprintf("%v", raw)
+		if old == newRaw {
+			return
+		}
+		if !hotReloadable {
+			diffs <- ConfigDiff{Field: fieldName, Old: old, New: newRaw,
+				Err: fmt.Errorf("%s is not hot-reloadable (env %s); restart rw_core to apply it", fieldName, envVar)}
+			return
+		}
+		if err := setField(field, newRaw); err != nil {
+			diffs <- ConfigDiff{Field: fieldName, Old: old, New: newRaw, Err: err}
+			return
+		}
+		diffs <- ConfigDiff{Field: fieldName, Old: old, New: newRaw}
+	})
+}
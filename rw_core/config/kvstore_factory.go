@@ -0,0 +1,63 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+)
+
+const (
+	// ConsulStoreName and EtcdStoreName are already declared above; RedisStoreName rounds out
+	// the set of KVStoreType values the factory knows how to build a client for.
+	RedisStoreName = "redis"
+)
+
+// KVBackendFactory builds a kvstore.Client for a given RWCoreFlags configuration. Each
+// supported KVStoreType (etcd, consul, redis) registers one of these so setupKVClient no longer
+// has to hardcode kvstore.NewEtcdClient.
+type KVBackendFactory func(cf *RWCoreFlags) (kvstore.Client, error)
+
+var kvBackendFactories = map[string]KVBackendFactory{
+	EtcdStoreName: func(cf *RWCoreFlags) (kvstore.Client, error) {
+		return kvstore.NewEtcdClient(cf.KVStoreAddress, cf.KVStoreTimeout, log.FatalLevel)
+	},
+	ConsulStoreName: func(cf *RWCoreFlags) (kvstore.Client, error) {
+		return kvstore.NewConsulClient(cf.KVStoreAddress, cf.KVStoreTimeout, log.FatalLevel)
+	},
+	RedisStoreName: func(cf *RWCoreFlags) (kvstore.Client, error) {
+		return kvstore.NewRedisClient(cf.KVStoreAddress, cf.KVStoreTimeout, false)
+	},
+}
+
+// RegisterKVBackendFactory lets a driver (or a test) register/override the factory used for a
+// given KVStoreType, e.g. a mock backend keyed under EtcdStoreName for unit tests.
+func RegisterKVBackendFactory(storeType string, factory KVBackendFactory) {
+	kvBackendFactories[storeType] = factory
+}
+
+// NewKVClient builds a kvstore.Client for cf.KVStoreType, returning an error instead of silently
+// falling back to etcd when the type is unknown or unregistered.
+func NewKVClient(cf *RWCoreFlags) (kvstore.Client, error) {
+	factory, ok := kvBackendFactories[cf.KVStoreType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported-kv-store-type: %s", cf.KVStoreType)
+	}
+	return factory(cf)
+}
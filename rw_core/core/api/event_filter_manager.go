@@ -0,0 +1,333 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+	"github.com/opencord/voltha-protos/v3/go/voltha"
+)
+
+// eventFilterKVPrefix namespaces persisted filters under the core's existing KV store, the same
+// one device.Manager uses for device state, so a filter created before a restart is still there
+// afterwards.
+const eventFilterKVPrefix = "service/voltha/event_filters"
+
+// voltha.EventFilter carries only match rules (EventFilterRule.Key/Value pairs), so the
+// suppress/rate-limit/forward action is encoded as two reserved rule keys rather than a new proto
+// field: a "action" rule whose value is one of the actionValue* constants, and, for rate
+// limiting, a "rate_limit_seconds" rule giving the minimum interval between forwarded events.
+const (
+	ruleKeyAction        = "action"
+	ruleKeyRateLimitSecs = "rate_limit_seconds"
+
+	actionValueForward   = "forward"
+	actionValueSuppress  = "suppress"
+	actionValueRateLimit = "rate_limit"
+)
+
+// FilterAction is what EventFilterManager.Evaluate tells the caller to do with a matched event.
+type FilterAction int
+
+const (
+	ActionForward FilterAction = iota
+	ActionSuppress
+	ActionRateLimited // matched a rate-limit filter but arrived inside the suppression window
+)
+
+// IncomingEvent is the classification EventFilterManager.Evaluate matches filter rules against.
+// KPI, alarm, and device-state-change events are all reducible to a category/sub-category/type/
+// severity plus a bag of named fields that a rule's regex can match against.
+type IncomingEvent struct {
+	DeviceID    string
+	Category    string
+	SubCategory string
+	Type        string
+	Severity    string
+	Fields      map[string]string
+}
+
+// fieldOf returns the value Evaluate should match a rule's key against: the well-known
+// classification fields by name, falling back to Fields for anything else.
+func (e *IncomingEvent) fieldOf(key string) (string, bool) {
+	switch key {
+	case "category":
+		return e.Category, true
+	case "sub_category":
+		return e.SubCategory, true
+	case "type":
+		return e.Type, true
+	case "severity":
+		return e.Severity, true
+	default:
+		v, ok := e.Fields[key]
+		return v, ok
+	}
+}
+
+// compiledRule is one EventFilterRule pre-parsed at Create/Update time so Evaluate never compiles
+// a regex on the hot path.
+type compiledRule struct {
+	key   string
+	value string
+	regex *regexp.Regexp // nil for the reserved action/rate-limit keys
+}
+
+// storedFilter is what EventFilterManager keeps per filter ID: the NBI-facing proto (returned
+// verbatim by Get/List) plus the pre-compiled rules and rate-limiter state Evaluate needs.
+type storedFilter struct {
+	filter *voltha.EventFilter
+	rules  []compiledRule
+	action FilterAction
+	// rateLimit and lastForwarded are only meaningful when action == ActionRateLimited's
+	// underlying rate_limit action; guarded by mu since Evaluate can run concurrently with itself
+	// across goroutines handling different events for the same device.
+	mu            sync.Mutex
+	rateLimit     time.Duration
+	lastForwarded time.Time
+}
+
+// EventFilterManager implements the matching/persistence logic backing NBIHandler's
+// Create/Update/Delete/Get/ListEventFilter RPCs.
+type EventFilterManager struct {
+	mu      sync.RWMutex
+	filters map[string]*storedFilter // keyed by EventFilter.Id
+	kvStore kvstore.Client
+}
+
+// NewEventFilterManager returns an EventFilterManager backed by kvStore, loading any filters a
+// previous core run already persisted there.
+func NewEventFilterManager(kvStore kvstore.Client) *EventFilterManager {
+	m := &EventFilterManager{
+		filters: make(map[string]*storedFilter),
+		kvStore: kvStore,
+	}
+	m.loadFromKV()
+	return m
+}
+
+func (m *EventFilterManager) loadFromKV() {
+	if m.kvStore == nil {
+		return
+	}
+	pairs, err := m.kvStore.List(eventFilterKVPrefix)
+	if err != nil {
+		logger.Warnw("event-filter-kv-list-failed", log.Fields{"error": err})
+		return
+	}
+	for key, pair := range pairs {
+		data, ok := pair.Value.([]byte)
+		if !ok {
+			logger.Warnw("event-filter-kv-unexpected-value-type", log.Fields{"key": key})
+			continue
+		}
+		filter := &voltha.EventFilter{}
+		if err := json.Unmarshal(data, filter); err != nil {
+			logger.Warnw("event-filter-kv-unmarshal-failed", log.Fields{"key": key, "error": err})
+			continue
+		}
+		sf, err := newStoredFilter(filter)
+		if err != nil {
+			logger.Warnw("event-filter-kv-invalid-rules", log.Fields{"key": key, "error": err})
+			continue
+		}
+		m.filters[filter.Id] = sf
+	}
+}
+
+func (m *EventFilterManager) persist(filter *voltha.EventFilter) error {
+	if m.kvStore == nil {
+		return nil
+	}
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return fmt.Errorf("event-filter-marshal-%s: %w", filter.Id, err)
+	}
+	return m.kvStore.Put(eventFilterKVPrefix+"/"+filter.Id, data)
+}
+
+// newStoredFilter validates filter's rules and, if they're all well-formed, returns the
+// storedFilter Evaluate will match events against.
+func newStoredFilter(filter *voltha.EventFilter) (*storedFilter, error) {
+	sf := &storedFilter{filter: filter, action: ActionForward}
+	for _, rule := range filter.Rules {
+		if rule == nil || rule.Key == "" {
+			return nil, fmt.Errorf("rule missing key")
+		}
+		switch rule.Key {
+		case ruleKeyAction:
+			switch rule.Value {
+			case actionValueForward:
+				sf.action = ActionForward
+			case actionValueSuppress:
+				sf.action = ActionSuppress
+			case actionValueRateLimit:
+				sf.action = ActionRateLimited
+			default:
+				return nil, fmt.Errorf("rule %q: unknown action %q", ruleKeyAction, rule.Value)
+			}
+		case ruleKeyRateLimitSecs:
+			secs, err := time.ParseDuration(rule.Value + "s")
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: %w", ruleKeyRateLimitSecs, err)
+			}
+			sf.rateLimit = secs
+		default:
+			re, err := regexp.Compile(rule.Value)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid regex %q: %w", rule.Key, rule.Value, err)
+			}
+			sf.rules = append(sf.rules, compiledRule{key: rule.Key, value: rule.Value, regex: re})
+		}
+	}
+	if sf.action == ActionRateLimited && sf.rateLimit <= 0 {
+		return nil, fmt.Errorf("rule %q: rate_limit action requires a %q rule", ruleKeyAction, ruleKeyRateLimitSecs)
+	}
+	return sf, nil
+}
+
+// matches reports whether every non-reserved rule's regex matches the corresponding field of ev;
+// a rule naming a field ev doesn't have never matches.
+func (sf *storedFilter) matches(ev *IncomingEvent) bool {
+	for _, rule := range sf.rules {
+		val, ok := ev.fieldOf(rule.key)
+		if !ok || !rule.regex.MatchString(val) {
+			return false
+		}
+	}
+	return true
+}
+
+// Create validates filter's rules and stores it, failing if its Id is already in use.
+func (m *EventFilterManager) Create(filter *voltha.EventFilter) (*voltha.EventFilter, error) {
+	if filter.Id == "" {
+		return nil, fmt.Errorf("event-filter-create: filter has no id")
+	}
+	sf, err := newStoredFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("event-filter-create-%s: %w", filter.Id, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.filters[filter.Id]; exists {
+		return nil, fmt.Errorf("event-filter-create-%s: already exists", filter.Id)
+	}
+	if err := m.persist(filter); err != nil {
+		return nil, err
+	}
+	m.filters[filter.Id] = sf
+	return filter, nil
+}
+
+// Update replaces the rules of an existing filter, failing if its Id is not known.
+func (m *EventFilterManager) Update(filter *voltha.EventFilter) (*voltha.EventFilter, error) {
+	if filter.Id == "" {
+		return nil, fmt.Errorf("event-filter-update: filter has no id")
+	}
+	sf, err := newStoredFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("event-filter-update-%s: %w", filter.Id, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.filters[filter.Id]; !exists {
+		return nil, fmt.Errorf("event-filter-update-%s: not found", filter.Id)
+	}
+	if err := m.persist(filter); err != nil {
+		return nil, err
+	}
+	m.filters[filter.Id] = sf
+	return filter, nil
+}
+
+// Delete removes a filter by Id; deleting an unknown Id is not an error, matching the model
+// proxy's usual delete-is-idempotent convention elsewhere in this package.
+func (m *EventFilterManager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.filters, id)
+	if m.kvStore == nil {
+		return nil
+	}
+	return m.kvStore.Delete(eventFilterKVPrefix + "/" + id)
+}
+
+// GetByDevice returns every filter registered against deviceId.
+func (m *EventFilterManager) GetByDevice(deviceID string) *voltha.EventFilters {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := &voltha.EventFilters{}
+	for _, sf := range m.filters {
+		if sf.filter.DeviceId == deviceID {
+			result.Filters = append(result.Filters, sf.filter)
+		}
+	}
+	return result
+}
+
+// List returns every filter known to the system, regardless of device.
+func (m *EventFilterManager) List() *voltha.EventFilters {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := &voltha.EventFilters{}
+	for _, sf := range m.filters {
+		result.Filters = append(result.Filters, sf.filter)
+	}
+	return result
+}
+
+// Evaluate matches ev against every filter registered for ev.DeviceID and returns the action of
+// the first one that matches: ActionSuppress or ActionRateLimited (inside its window) short
+// circuit and stop checking further filters, since the event has already been decided against;
+// ActionForward keeps checking in case a later filter suppresses it. An event matching no filter
+// forwards by default.
+func (m *EventFilterManager) Evaluate(ev *IncomingEvent) FilterAction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, sf := range m.filters {
+		if sf.filter.DeviceId != "" && sf.filter.DeviceId != ev.DeviceID {
+			continue
+		}
+		if !sf.matches(ev) {
+			continue
+		}
+		switch sf.action {
+		case ActionSuppress:
+			return ActionSuppress
+		case ActionRateLimited:
+			sf.mu.Lock()
+			now := time.Now()
+			limited := now.Sub(sf.lastForwarded) < sf.rateLimit
+			if !limited {
+				sf.lastForwarded = now
+			}
+			sf.mu.Unlock()
+			if limited {
+				return ActionRateLimited
+			}
+		}
+	}
+	return ActionForward
+}
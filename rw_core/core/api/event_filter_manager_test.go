@@ -0,0 +1,161 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
+	"github.com/opencord/voltha-protos/v3/go/voltha"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKVClient is a minimal in-memory kvstore.Client, just enough of the surface
+// EventFilterManager uses, so its persistence/restart behavior can be tested without a real etcd.
+type fakeKVClient struct {
+	kvstore.Client
+	data map[string][]byte
+}
+
+func newFakeKVClient() *fakeKVClient {
+	return &fakeKVClient{data: make(map[string][]byte)}
+}
+
+func (c *fakeKVClient) Put(key string, value interface{}) error {
+	c.data[key] = value.([]byte)
+	return nil
+}
+
+func (c *fakeKVClient) Get(key string) (*kvstore.KVPair, error) {
+	v, ok := c.data[key]
+	if !ok {
+		return nil, nil
+	}
+	return &kvstore.KVPair{Key: key, Value: v}, nil
+}
+
+func (c *fakeKVClient) Delete(key string) error {
+	delete(c.data, key)
+	return nil
+}
+
+func (c *fakeKVClient) List(prefix string) (map[string]*kvstore.KVPair, error) {
+	result := make(map[string]*kvstore.KVPair)
+	for k, v := range c.data {
+		if strings.HasPrefix(k, prefix) {
+			result[k] = &kvstore.KVPair{Key: k, Value: v}
+		}
+	}
+	return result, nil
+}
+
+func ruleFilter(id, deviceID string, rules ...*voltha.EventFilterRule) *voltha.EventFilter {
+	return &voltha.EventFilter{Id: id, DeviceId: deviceID, Rules: rules}
+}
+
+func TestEventFilterManagerCreateRejectsBadRegex(t *testing.T) {
+	m := NewEventFilterManager(nil)
+	filter := ruleFilter("f1", "dev1", &voltha.EventFilterRule{Key: "category", Value: "("})
+	_, err := m.Create(filter)
+	assert.Error(t, err)
+}
+
+func TestEventFilterManagerSuppressMatchingEvent(t *testing.T) {
+	m := NewEventFilterManager(nil)
+	filter := ruleFilter("f1", "dev1",
+		&voltha.EventFilterRule{Key: "category", Value: "^KPI$"},
+		&voltha.EventFilterRule{Key: ruleKeyAction, Value: actionValueSuppress},
+	)
+	_, err := m.Create(filter)
+	assert.NoError(t, err)
+
+	action := m.Evaluate(&IncomingEvent{DeviceID: "dev1", Category: "KPI"})
+	assert.Equal(t, ActionSuppress, action)
+
+	action = m.Evaluate(&IncomingEvent{DeviceID: "dev1", Category: "ALARM"})
+	assert.Equal(t, ActionForward, action)
+
+	action = m.Evaluate(&IncomingEvent{DeviceID: "dev2", Category: "KPI"})
+	assert.Equal(t, ActionForward, action)
+}
+
+func TestEventFilterManagerRateLimit(t *testing.T) {
+	m := NewEventFilterManager(nil)
+	filter := ruleFilter("f1", "dev1",
+		&voltha.EventFilterRule{Key: "category", Value: "^ALARM$"},
+		&voltha.EventFilterRule{Key: ruleKeyAction, Value: actionValueRateLimit},
+		&voltha.EventFilterRule{Key: ruleKeyRateLimitSecs, Value: "60"},
+	)
+	_, err := m.Create(filter)
+	assert.NoError(t, err)
+
+	ev := &IncomingEvent{DeviceID: "dev1", Category: "ALARM"}
+	assert.Equal(t, ActionForward, m.Evaluate(ev))
+	assert.Equal(t, ActionRateLimited, m.Evaluate(ev))
+}
+
+func TestEventFilterManagerUpdateAndDelete(t *testing.T) {
+	m := NewEventFilterManager(nil)
+	filter := ruleFilter("f1", "dev1", &voltha.EventFilterRule{Key: "category", Value: "^KPI$"})
+	_, err := m.Create(filter)
+	assert.NoError(t, err)
+
+	_, err = m.Update(ruleFilter("f1", "dev1",
+		&voltha.EventFilterRule{Key: "category", Value: "^KPI$"},
+		&voltha.EventFilterRule{Key: ruleKeyAction, Value: actionValueSuppress},
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, ActionSuppress, m.Evaluate(&IncomingEvent{DeviceID: "dev1", Category: "KPI"}))
+
+	_, err = m.Update(ruleFilter("missing", "dev1"))
+	assert.Error(t, err)
+
+	assert.NoError(t, m.Delete("f1"))
+	assert.Equal(t, ActionForward, m.Evaluate(&IncomingEvent{DeviceID: "dev1", Category: "KPI"}))
+}
+
+func TestEventFilterManagerGetByDeviceAndList(t *testing.T) {
+	m := NewEventFilterManager(nil)
+	_, err := m.Create(ruleFilter("f1", "dev1"))
+	assert.NoError(t, err)
+	_, err = m.Create(ruleFilter("f2", "dev2"))
+	assert.NoError(t, err)
+
+	assert.Len(t, m.GetByDevice("dev1").Filters, 1)
+	assert.Len(t, m.List().Filters, 2)
+}
+
+// TestEventFilterManagerPersistsAcrossRestart simulates a core restart by constructing a second
+// EventFilterManager on top of the same backing KV store and checking it rediscovers the filter.
+func TestEventFilterManagerPersistsAcrossRestart(t *testing.T) {
+	kvClient := newFakeKVClient()
+	m := NewEventFilterManager(kvClient)
+	filter := ruleFilter("f1", "dev1",
+		&voltha.EventFilterRule{Key: "category", Value: "^KPI$"},
+		&voltha.EventFilterRule{Key: ruleKeyAction, Value: actionValueSuppress},
+	)
+	_, err := m.Create(filter)
+	assert.NoError(t, err)
+
+	restarted := NewEventFilterManager(kvClient)
+	assert.Len(t, restarted.List().Filters, 1)
+	assert.Equal(t, ActionSuppress, restarted.Evaluate(&IncomingEvent{DeviceID: "dev1", Category: "KPI"}))
+
+	assert.NoError(t, restarted.Delete("f1"))
+	reloaded := NewEventFilterManager(kvClient)
+	assert.Len(t, reloaded.List().Filters, 0)
+}
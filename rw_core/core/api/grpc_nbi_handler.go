@@ -23,15 +23,19 @@ import (
 	"errors"
 	"github.com/golang/protobuf/ptypes/empty"
 	da "github.com/opencord/voltha-go/common/core/northbound/grpc"
+	"github.com/opencord/voltha-go/db/model"
 	"github.com/opencord/voltha-go/rw_core/core/adapter"
 	"github.com/opencord/voltha-go/rw_core/core/device"
+	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
 	"github.com/opencord/voltha-lib-go/v3/pkg/log"
 	"github.com/opencord/voltha-lib-go/v3/pkg/version"
 	"github.com/opencord/voltha-protos/v3/go/common"
 	"github.com/opencord/voltha-protos/v3/go/omci"
 	"github.com/opencord/voltha-protos/v3/go/openflow_13"
 	"github.com/opencord/voltha-protos/v3/go/voltha"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"io"
 	"sync"
@@ -47,26 +51,47 @@ const (
 
 // NBIHandler represent attributes of API handler
 type NBIHandler struct {
-	deviceMgr            *device.Manager
-	logicalDeviceMgr     *device.LogicalManager
-	adapterMgr           *adapter.Manager
-	packetInQueue        chan openflow_13.PacketIn
-	changeEventQueue     chan openflow_13.ChangeEvent
-	packetInQueueDone    chan bool
-	changeEventQueueDone chan bool
+	deviceMgr         *device.Manager
+	logicalDeviceMgr  *device.LogicalManager
+	adapterMgr        *adapter.Manager
+	eventFilterMgr    *EventFilterManager
+	ofAgentMembership *OFAgentMembershipManager
+	operationTracker  *OperationTracker
+	packetInHub       *streamHub[openflow_13.PacketIn]
+	changeEventHub    *streamHub[openflow_13.ChangeEvent]
+	// modelAdd/Update/RemoveCallbacks feed modelChangeBroker (see SubscribeChangeEvents); they are
+	// invoked directly from this handler's own model-mutating RPCs (e.g. the EventFilter CRUD
+	// below) rather than from a real db/model proxy/branch, since no such global mutation
+	// callback plumbing exists anywhere in this tree to register against.
+	modelAddCallbacks    *model.CallbackRegistry[model.ModelMutation]
+	modelUpdateCallbacks *model.CallbackRegistry[model.ModelMutation]
+	modelRemoveCallbacks *model.CallbackRegistry[model.ModelMutation]
+	modelChangeBroker    *model.ChangeEventBroker
 	da.DefaultAPIHandler
 }
 
+// changeEventBrokerRingSize bounds how many buffered-but-unsent model ChangeEvents a
+// SubscribeChangeEvents caller's subscription can accumulate before the oldest is dropped.
+const changeEventBrokerRingSize = 64
+
 // NewAPIHandler creates API handler instance
-func NewAPIHandler(deviceMgr *device.Manager, logicalDeviceMgr *device.LogicalManager, adapterMgr *adapter.Manager) *NBIHandler {
+func NewAPIHandler(deviceMgr *device.Manager, logicalDeviceMgr *device.LogicalManager, adapterMgr *adapter.Manager, kvClient kvstore.Client) *NBIHandler {
+	addCallbacks := model.NewCallbackRegistry[model.ModelMutation]("POST_ADD")
+	updateCallbacks := model.NewCallbackRegistry[model.ModelMutation]("POST_UPDATE")
+	removeCallbacks := model.NewCallbackRegistry[model.ModelMutation]("POST_REMOVE")
 	return &NBIHandler{
 		deviceMgr:            deviceMgr,
 		logicalDeviceMgr:     logicalDeviceMgr,
 		adapterMgr:           adapterMgr,
-		packetInQueue:        make(chan openflow_13.PacketIn, 100),
-		changeEventQueue:     make(chan openflow_13.ChangeEvent, 100),
-		packetInQueueDone:    make(chan bool, 1),
-		changeEventQueueDone: make(chan bool, 1),
+		eventFilterMgr:       NewEventFilterManager(kvClient),
+		ofAgentMembership:    NewOFAgentMembershipManager(kvClient),
+		operationTracker:     NewOperationTracker(kvClient),
+		packetInHub:          newStreamHub[openflow_13.PacketIn](),
+		changeEventHub:       newStreamHub[openflow_13.ChangeEvent](),
+		modelAddCallbacks:    addCallbacks,
+		modelUpdateCallbacks: updateCallbacks,
+		modelRemoveCallbacks: removeCallbacks,
+		modelChangeBroker:    model.NewChangeEventBroker(addCallbacks, updateCallbacks, removeCallbacks, changeEventBrokerRingSize, nil),
 	}
 }
 
@@ -131,9 +156,31 @@ func (handler *NBIHandler) DisableLogicalDevicePort(ctx context.Context, id *vol
 	return waitForNilResponseOnSuccess(ctx, ch)
 }
 
+// authorizeWrite rejects a write RPC targeting logicalDeviceID if the caller identified itself as
+// an OFAgent (via OfAgentIDFromContext) that Subscribe/arbitration did not make the leader for
+// that device. A caller with no OFAgent id on its context - an NBI client other than an OFAgent -
+// is not subject to leader arbitration at all.
+func (handler *NBIHandler) authorizeWrite(ctx context.Context, logicalDeviceID string) error {
+	ofAgentID, ok := OfAgentIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	isLeader, err := handler.ofAgentMembership.Arbitrate(logicalDeviceID, ofAgentID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "%s", err)
+	}
+	if !isLeader {
+		return status.Errorf(codes.PermissionDenied, "ofagent-%s-is-not-leader-for-logical-device-%s", ofAgentID, logicalDeviceID)
+	}
+	return nil
+}
+
 // UpdateLogicalDeviceFlowTable updates logical device flow table
 func (handler *NBIHandler) UpdateLogicalDeviceFlowTable(ctx context.Context, flow *openflow_13.FlowTableUpdate) (*empty.Empty, error) {
 	logger.Debugw("UpdateLogicalDeviceFlowTable-request", log.Fields{"flow": flow, "test": common.TestModeKeys_api_test.String()})
+	if err := handler.authorizeWrite(ctx, flow.Id); err != nil {
+		return nil, err
+	}
 
 	ch := make(chan interface{})
 	defer close(ch)
@@ -144,12 +191,65 @@ func (handler *NBIHandler) UpdateLogicalDeviceFlowTable(ctx context.Context, flo
 // UpdateLogicalDeviceFlowGroupTable updates logical device flow group table
 func (handler *NBIHandler) UpdateLogicalDeviceFlowGroupTable(ctx context.Context, flow *openflow_13.FlowGroupTableUpdate) (*empty.Empty, error) {
 	logger.Debugw("UpdateLogicalDeviceFlowGroupTable-request", log.Fields{"flow": flow, "test": common.TestModeKeys_api_test.String()})
+	if err := handler.authorizeWrite(ctx, flow.Id); err != nil {
+		return nil, err
+	}
 	ch := make(chan interface{})
 	defer close(ch)
 	go handler.logicalDeviceMgr.UpdateGroupTable(ctx, flow.Id, flow.GroupMod, ch)
 	return waitForNilResponseOnSuccess(ctx, ch)
 }
 
+// FlowTableBatchEntry is one ordered item in a FlowTableBatchUpdate. Exactly one of FlowMod or
+// GroupMod is set; logicalDeviceMgr turns it into a device.BatchMutation bound to the matching
+// single-mod update API before running the batch.
+type FlowTableBatchEntry struct {
+	FlowMod  *openflow_13.OfpFlowMod
+	GroupMod *openflow_13.OfpGroupMod
+}
+
+// FlowTableBatchUpdate is the request for UpdateLogicalDeviceFlowTableBatch: an ordered list of
+// flow and group mods to apply to a single logical device's tables under one lock.
+type FlowTableBatchUpdate struct {
+	Id      string
+	Mode    device.BatchUpdateMode
+	Entries []FlowTableBatchEntry
+}
+
+// FlowTableBatchResponse is the response for UpdateLogicalDeviceFlowTableBatch: one
+// device.BatchEntryResult per submitted entry, in submission order.
+type FlowTableBatchResponse struct {
+	Results []device.BatchEntryResult
+}
+
+// UpdateLogicalDeviceFlowTableBatch applies an ordered list of flow and group mods to a logical
+// device under a single lock in logicalDeviceMgr. In BatchBestEffort mode every entry is attempted
+// regardless of earlier failures, each reporting its own outcome; in BatchAtomic mode the first
+// failed entry aborts the batch and rolls back every entry already applied, leaving the logical
+// device's flow and group tables unchanged.
+func (handler *NBIHandler) UpdateLogicalDeviceFlowTableBatch(ctx context.Context, batch *FlowTableBatchUpdate) (*FlowTableBatchResponse, error) {
+	logger.Debugw("UpdateLogicalDeviceFlowTableBatch-request", log.Fields{"id": batch.Id, "entries": len(batch.Entries), "mode": batch.Mode})
+
+	ch := make(chan interface{})
+	defer close(ch)
+	go handler.logicalDeviceMgr.UpdateFlowTableBatch(ctx, batch.Id, batch.Entries, batch.Mode, ch)
+
+	select {
+	case res := <-ch:
+		if results, ok := res.([]device.BatchEntryResult); ok {
+			return &FlowTableBatchResponse{Results: results}, nil
+		}
+		if err, ok := res.(error); ok {
+			return nil, err
+		}
+		logger.Warnw("unexpected-return-type", log.Fields{"result": res})
+		return nil, status.Errorf(codes.Internal, "%v", res)
+	case <-ctx.Done():
+		logger.Debug("update-logical-device-flow-table-batch-client-timeout")
+		return nil, ctx.Err()
+	}
+}
+
 // GetDevice must be implemented in the read-only containers - should it also be implemented here?
 func (handler *NBIHandler) GetDevice(ctx context.Context, id *voltha.ID) (*voltha.Device, error) {
 	logger.Debugw("GetDevice-request", log.Fields{"id": id})
@@ -175,6 +275,52 @@ func (handler *NBIHandler) ListDeviceIds(ctx context.Context, empty *empty.Empty
 	return handler.deviceMgr.ListDeviceIds()
 }
 
+// DeviceFilter narrows StreamDevices to devices matching every set field; a field left at its
+// zero value (empty string, or the enum's UNKNOWN value) matches any device.
+type DeviceFilter struct {
+	ParentId   string
+	AdminState voltha.AdminState_AdminState
+	OperState  voltha.OperStatus_OperStatus
+	DeviceType string
+}
+
+// devicePageSize is the number of devices deviceMgr.ListDevicesPage fetches per round trip, so
+// StreamDevices never materializes the whole device list at once.
+const devicePageSize = 100
+
+// StreamDevices pushes every device matching filter to the caller a page at a time, pulling pages
+// from deviceMgr.ListDevicesPage rather than building the full voltha.Devices slice ListDevices
+// returns, so a deployment with thousands of ONUs doesn't pay for one giant response.
+func (handler *NBIHandler) StreamDevices(filter *DeviceFilter, stream voltha.VolthaService_StreamDevicesServer) error {
+	logger.Debugw("StreamDevices-request", log.Fields{"filter": filter})
+
+	ctx := stream.Context()
+	pageToken := ""
+	for {
+		devices, nextPageToken, err := handler.deviceMgr.ListDevicesPage(ctx, filter, pageToken, devicePageSize)
+		if err != nil {
+			return err
+		}
+		for _, d := range devices {
+			if err := stream.Send(d); err != nil {
+				logger.Errorw("failed-to-send-device", log.Fields{"error": err, "deviceId": d.Id})
+				return err
+			}
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+
+		select {
+		case <-ctx.Done():
+			logger.Debug("streamDevices-client-timeout")
+			return ctx.Err()
+		default:
+		}
+	}
+}
+
 //ReconcileDevices is a request to a voltha core to managed a list of devices  based on their IDs
 func (handler *NBIHandler) ReconcileDevices(ctx context.Context, ids *voltha.IDs) (*empty.Empty, error) {
 	logger.Debug("ReconcileDevices")
@@ -292,6 +438,97 @@ func (handler *NBIHandler) DeleteDevice(ctx context.Context, id *voltha.ID) (*em
 	return waitForNilResponseOnSuccess(ctx, ch)
 }
 
+// bulkOpConcurrency bounds how many of a bulk RPC's per-device operations run at once, so a
+// request against thousands of ids doesn't open thousands of simultaneous adapter calls.
+const bulkOpConcurrency = 20
+
+// BulkOperationResult is one id's outcome from a bulk device RPC.
+type BulkOperationResult struct {
+	Id    string
+	Error string
+}
+
+// BulkOperationResponse is the response for EnableDevices, DisableDevices, RebootDevices, and
+// DeleteDevices: one BulkOperationResult per id in ids, in the same order as ids even though
+// entries run concurrently.
+type BulkOperationResponse struct {
+	Results []BulkOperationResult
+}
+
+// runBulkDeviceOp runs op(ctx, id) for every id in ids, at most bulkOpConcurrency at a time, and
+// collects each id's outcome into a BulkOperationResponse. op is expected to mirror the
+// single-device RPCs: it drives deviceMgr through its channel-based API and returns the error, if
+// any, that came back on that channel.
+func runBulkDeviceOp(ctx context.Context, ids *voltha.IDs, op func(ctx context.Context, id string) error) *BulkOperationResponse {
+	results := make([]BulkOperationResult, len(ids.Items))
+	sem := make(chan struct{}, bulkOpConcurrency)
+	var wg sync.WaitGroup
+
+	for i, id := range ids.Items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id *voltha.ID) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result := BulkOperationResult{Id: id.Id}
+			if err := op(ctx, id.Id); err != nil {
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i, id)
+	}
+	wg.Wait()
+
+	return &BulkOperationResponse{Results: results}
+}
+
+// deviceOpChannelFunc adapts one of deviceMgr's channel-based single-device operations (e.g.
+// EnableDevice) to the plain (ctx, id) -> error shape runBulkDeviceOp expects.
+func deviceOpChannelFunc(op func(ctx context.Context, id *voltha.ID, ch chan interface{})) func(ctx context.Context, id string) error {
+	return func(ctx context.Context, id string) error {
+		ch := make(chan interface{})
+		defer close(ch)
+		go op(ctx, &voltha.ID{Id: id}, ch)
+		select {
+		case res := <-ch:
+			if res == nil {
+				return nil
+			}
+			if err, ok := res.(error); ok {
+				return err
+			}
+			return status.Errorf(codes.Internal, "%v", res)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// EnableDevices activates every device in ids, per EnableDevice, in bounded parallelism.
+func (handler *NBIHandler) EnableDevices(ctx context.Context, ids *voltha.IDs) (*BulkOperationResponse, error) {
+	logger.Debugw("EnableDevices-request", log.Fields{"ids": len(ids.Items)})
+	return runBulkDeviceOp(ctx, ids, deviceOpChannelFunc(handler.deviceMgr.EnableDevice)), nil
+}
+
+// DisableDevices disables every device in ids, per DisableDevice, in bounded parallelism.
+func (handler *NBIHandler) DisableDevices(ctx context.Context, ids *voltha.IDs) (*BulkOperationResponse, error) {
+	logger.Debugw("DisableDevices-request", log.Fields{"ids": len(ids.Items)})
+	return runBulkDeviceOp(ctx, ids, deviceOpChannelFunc(handler.deviceMgr.DisableDevice)), nil
+}
+
+// RebootDevices reboots every device in ids, per RebootDevice, in bounded parallelism.
+func (handler *NBIHandler) RebootDevices(ctx context.Context, ids *voltha.IDs) (*BulkOperationResponse, error) {
+	logger.Debugw("RebootDevices-request", log.Fields{"ids": len(ids.Items)})
+	return runBulkDeviceOp(ctx, ids, deviceOpChannelFunc(handler.deviceMgr.RebootDevice)), nil
+}
+
+// DeleteDevices removes every device in ids from the data model, per DeleteDevice, in bounded
+// parallelism.
+func (handler *NBIHandler) DeleteDevices(ctx context.Context, ids *voltha.IDs) (*BulkOperationResponse, error) {
+	logger.Debugw("DeleteDevices-request", log.Fields{"ids": len(ids.Items)})
+	return runBulkDeviceOp(ctx, ids, deviceOpChannelFunc(handler.deviceMgr.DeleteDevice)), nil
+}
+
 // ListDevicePorts returns the ports details for a specific device entry
 func (handler *NBIHandler) ListDevicePorts(ctx context.Context, id *voltha.ID) (*voltha.Ports, error) {
 	logger.Debugw("listdeviceports-request", log.Fields{"id": id})
@@ -443,6 +680,41 @@ func (handler *NBIHandler) RevertImageUpdate(ctx context.Context, img *voltha.Im
 	return handler.processImageRequest(ctx, img, RevertImage)
 }
 
+// StreamImageDownloadStatus streams image-download progress - percentage, bytes transferred, and
+// state transitions - for img as the adapter reports them, so a client doesn't have to poll
+// GetImageDownloadStatus in a loop to watch a download through to completion.
+func (handler *NBIHandler) StreamImageDownloadStatus(img *voltha.ImageDownload, stream voltha.VolthaService_StreamImageDownloadStatusServer) error {
+	logger.Debugw("StreamImageDownloadStatus-request", log.Fields{"img": *img})
+
+	ch := make(chan interface{})
+	defer close(ch)
+	go handler.deviceMgr.SubscribeImageDownloadProgress(stream.Context(), img, ch)
+
+	for {
+		select {
+		case res, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err, ok := res.(error); ok {
+				return err
+			}
+			update, ok := res.(*voltha.ImageDownload)
+			if !ok {
+				logger.Warnw("stream-image-download-status-unexpected-return-type", log.Fields{"result": res})
+				continue
+			}
+			if err := stream.Send(update); err != nil {
+				logger.Errorw("failed-to-send-image-download-status", log.Fields{"error": err})
+				return err
+			}
+		case <-stream.Context().Done():
+			logger.Debug("streamImageDownloadStatus-client-timeout")
+			return stream.Context().Err()
+		}
+	}
+}
+
 // GetImageDownloadStatus returns status of image download
 func (handler *NBIHandler) GetImageDownloadStatus(ctx context.Context, img *voltha.ImageDownload) (*voltha.ImageDownload, error) {
 	logger.Debugw("getImageDownloadStatus-request", log.Fields{"img": *img})
@@ -524,31 +796,58 @@ func (handler *NBIHandler) ListDevicePmConfigs(ctx context.Context, id *voltha.I
 	return handler.deviceMgr.ListPmConfigs(ctx, id.Id)
 }
 
+// eventFilterPath is the model path an event filter's ChangeEvents are published under, so a
+// SubscribeChangeEvents caller can scope its subscription with a ChangeEventFilter{PathPrefix:
+// "/event_filters"} the same way a real db/model proxy subscriber would scope by model path.
+func eventFilterPath(id string) string {
+	return "/event_filters/" + id
+}
+
 func (handler *NBIHandler) CreateEventFilter(ctx context.Context, filter *voltha.EventFilter) (*voltha.EventFilter, error) {
 	logger.Debugw("CreateEventFilter-request", log.Fields{"filter": *filter})
-	return nil, errors.New("UnImplemented")
+	created, err := handler.eventFilterMgr.Create(filter)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	if err := handler.modelAddCallbacks.Invoke(ctx, eventFilterPath(created.Id), model.ModelMutation{Path: eventFilterPath(created.Id), Data: created}); err != nil {
+		logger.Warnw("create-event-filter-change-event-failed", log.Fields{"id": created.Id, "error": err})
+	}
+	return created, nil
 }
 
 func (handler *NBIHandler) UpdateEventFilter(ctx context.Context, filter *voltha.EventFilter) (*voltha.EventFilter, error) {
 	logger.Debugw("UpdateEventFilter-request", log.Fields{"filter": *filter})
-	return nil, errors.New("UnImplemented")
+	updated, err := handler.eventFilterMgr.Update(filter)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	if err := handler.modelUpdateCallbacks.Invoke(ctx, eventFilterPath(updated.Id), model.ModelMutation{Path: eventFilterPath(updated.Id), Data: updated}); err != nil {
+		logger.Warnw("update-event-filter-change-event-failed", log.Fields{"id": updated.Id, "error": err})
+	}
+	return updated, nil
 }
 
 func (handler *NBIHandler) DeleteEventFilter(ctx context.Context, filterInfo *voltha.EventFilter) (*empty.Empty, error) {
 	logger.Debugw("DeleteEventFilter-request", log.Fields{"device-id": filterInfo.DeviceId, "filter-id": filterInfo.Id})
-	return nil, errors.New("UnImplemented")
+	if err := handler.eventFilterMgr.Delete(filterInfo.Id); err != nil {
+		return nil, status.Errorf(codes.Internal, "%s", err)
+	}
+	if err := handler.modelRemoveCallbacks.Invoke(ctx, eventFilterPath(filterInfo.Id), model.ModelMutation{Path: eventFilterPath(filterInfo.Id), Data: filterInfo}); err != nil {
+		logger.Warnw("delete-event-filter-change-event-failed", log.Fields{"id": filterInfo.Id, "error": err})
+	}
+	return &empty.Empty{}, nil
 }
 
 // GetEventFilter returns all the filters present for a device
 func (handler *NBIHandler) GetEventFilter(ctx context.Context, id *voltha.ID) (*voltha.EventFilters, error) {
 	logger.Debugw("GetEventFilter-request", log.Fields{"device-id": id})
-	return nil, errors.New("UnImplemented")
+	return handler.eventFilterMgr.GetByDevice(id.Id), nil
 }
 
 // ListEventFilters returns all the filters known to the system
 func (handler *NBIHandler) ListEventFilters(ctx context.Context, empty *empty.Empty) (*voltha.EventFilters, error) {
 	logger.Debug("ListEventFilter-request")
-	return nil, errors.New("UnImplemented")
+	return handler.eventFilterMgr.List(), nil
 }
 
 func (handler *NBIHandler) SelfTest(ctx context.Context, id *voltha.ID) (*voltha.SelfTestResponse, error) {
@@ -558,12 +857,14 @@ func (handler *NBIHandler) SelfTest(ctx context.Context, id *voltha.ID) (*voltha
 
 // StreamPacketsOut sends packets to adapter
 func (handler *NBIHandler) StreamPacketsOut(packets voltha.VolthaService_StreamPacketsOutServer) error {
-	logger.Debugw("StreamPacketsOut-request", log.Fields{"packets": packets})
+	ctx := packets.Context()
+	ofAgentID, _ := OfAgentIDFromContext(ctx)
+	logger.Debugw("StreamPacketsOut-request", log.Fields{"ofAgentId": ofAgentID})
 loop:
 	for {
 		select {
-		case <-packets.Context().Done():
-			logger.Infow("StreamPacketsOut-context-done", log.Fields{"packets": packets, "error": packets.Context().Err()})
+		case <-ctx.Done():
+			logger.Infow("StreamPacketsOut-context-done", log.Fields{"ofAgentId": ofAgentID, "error": ctx.Err()})
 			break loop
 		default:
 		}
@@ -571,19 +872,24 @@ loop:
 		packet, err := packets.Recv()
 
 		if err == io.EOF {
-			logger.Debugw("Received-EOF", log.Fields{"packets": packets})
+			logger.Debugw("Received-EOF", log.Fields{"ofAgentId": ofAgentID})
 			break loop
 		}
 
 		if err != nil {
-			logger.Errorw("Failed to receive packet out", log.Fields{"error": err})
+			logger.Errorw("Failed to receive packet out", log.Fields{"ofAgentId": ofAgentID, "error": err})
 			continue
 		}
 
-		handler.logicalDeviceMgr.PacketOut(packets.Context(), packet)
+		if err := handler.authorizeWrite(ctx, packet.Id); err != nil {
+			logger.Warnw("rejected-packet-out-from-non-leader", log.Fields{"ofAgentId": ofAgentID, "packet": packet, "error": err})
+			continue
+		}
+
+		handler.logicalDeviceMgr.PacketOut(ctx, packet)
 	}
 
-	logger.Debugw("StreamPacketsOut-request-done", log.Fields{"packets": packets})
+	logger.Debugw("StreamPacketsOut-request-done", log.Fields{"ofAgentId": ofAgentID})
 	return nil
 }
 
@@ -591,145 +897,155 @@ func (handler *NBIHandler) SendPacketIn(deviceID string, transationID string, pa
 	// TODO: Augment the OF PacketIn to include the transactionId
 	packetIn := openflow_13.PacketIn{Id: deviceID, PacketIn: packet}
 	logger.Debugw("SendPacketIn", log.Fields{"packetIn": packetIn})
-	handler.packetInQueue <- packetIn
-}
-
-type callTracker struct {
-	failedPacket interface{}
-}
-type streamTracker struct {
-	calls map[string]*callTracker
-	sync.Mutex
+	handler.packetInHub.publish(deviceID, packetIn)
 }
 
-var streamingTracker = &streamTracker{calls: make(map[string]*callTracker)}
+// ReceivePacketsIn streams packet-ins to one OFAgent subscriber. Several subscribers (e.g. an HA
+// OFAgent's replicas) can run this RPC at once, each getting its own fan-out channel from
+// packetInHub; a (re)connecting subscriber is first replayed packetInHub's buffered history
+// before the loop below switches it over to live traffic.
+func (handler *NBIHandler) ReceivePacketsIn(_ *empty.Empty, packetsIn voltha.VolthaService_ReceivePacketsInServer) error {
+	ctx := packetsIn.Context()
+	ofAgentID, _ := OfAgentIDFromContext(ctx)
+	logger.Debugw("ReceivePacketsIn-request", log.Fields{"ofAgentId": ofAgentID})
 
-func (handler *NBIHandler) getStreamingTracker(method string, done chan<- bool) *callTracker {
-	streamingTracker.Lock()
-	defer streamingTracker.Unlock()
-	if _, ok := streamingTracker.calls[method]; ok {
-		// bail out the other packet in thread
-		logger.Debugf("%s streaming call already running. Exiting it", method)
-		done <- true
-		logger.Debugf("Last %s exited. Continuing ...", method)
-	} else {
-		streamingTracker.calls[method] = &callTracker{failedPacket: nil}
-	}
-	return streamingTracker.calls[method]
-}
+	ch, backlog, unsubscribe := handler.packetInHub.subscribe()
+	defer unsubscribe()
 
-func (handler *NBIHandler) flushFailedPackets(tracker *callTracker) error {
-	if tracker.failedPacket != nil {
-		switch tracker.failedPacket.(type) {
-		case openflow_13.PacketIn:
-			logger.Debug("Enqueueing last failed packetIn")
-			handler.packetInQueue <- tracker.failedPacket.(openflow_13.PacketIn)
-		case openflow_13.ChangeEvent:
-			logger.Debug("Enqueueing last failed changeEvent")
-			handler.changeEventQueue <- tracker.failedPacket.(openflow_13.ChangeEvent)
+	for _, packet := range backlog {
+		if err := packetsIn.Send(&packet); err != nil {
+			logger.Errorw("failed-to-send-buffered-packet", log.Fields{"ofAgentId": ofAgentID, "error": err})
+			return err
 		}
 	}
-	return nil
-}
-
-// ReceivePacketsIn receives packets from adapter
-func (handler *NBIHandler) ReceivePacketsIn(empty *empty.Empty, packetsIn voltha.VolthaService_ReceivePacketsInServer) error {
-	var streamingTracker = handler.getStreamingTracker("ReceivePacketsIn", handler.packetInQueueDone)
-	logger.Debugw("ReceivePacketsIn-request", log.Fields{"packetsIn": packetsIn})
-
-	err := handler.flushFailedPackets(streamingTracker)
-	if err != nil {
-		logger.Errorw("unable-to-flush-failed-packets", log.Fields{"error": err})
-	}
 
-loop:
 	for {
 		select {
-		case packet := <-handler.packetInQueue:
+		case packet, ok := <-ch:
+			if !ok {
+				return nil
+			}
 			logger.Debugw("sending-packet-in", log.Fields{
-				"packet": hex.EncodeToString(packet.PacketIn.Data),
+				"ofAgentId": ofAgentID,
+				"packet":    hex.EncodeToString(packet.PacketIn.Data),
 			})
 			if err := packetsIn.Send(&packet); err != nil {
-				logger.Errorw("failed-to-send-packet", log.Fields{"error": err})
-				// save the last failed packet in
-				streamingTracker.failedPacket = packet
-			} else {
-				if streamingTracker.failedPacket != nil {
-					// reset last failed packet saved to avoid flush
-					streamingTracker.failedPacket = nil
-				}
+				logger.Errorw("failed-to-send-packet", log.Fields{"ofAgentId": ofAgentID, "error": err})
+				return err
 			}
-		case <-handler.packetInQueueDone:
-			logger.Debug("Another ReceivePacketsIn running. Bailing out ...")
-			break loop
+		case <-ctx.Done():
+			logger.Debugw("receivePacketsIn-client-disconnected", log.Fields{"ofAgentId": ofAgentID})
+			return ctx.Err()
 		}
 	}
-
-	//TODO: Find an elegant way to get out of the above loop when the Core is stopped
-	return nil
 }
 
+// SendChangeEvent is how a device agent reports a port-status change; it is evaluated against the
+// registered event filters before publishing, so a filter created through CreateEventFilter
+// actually suppresses/rate-limits something instead of only ever being matched by its own test.
 func (handler *NBIHandler) SendChangeEvent(deviceID string, portStatus *openflow_13.OfpPortStatus) {
 	// TODO: validate the type of portStatus parameter
 	//if _, ok := portStatus.(*openflow_13.OfpPortStatus); ok {
 	//}
+	incoming := &IncomingEvent{DeviceID: deviceID, Category: "DEVICE_EVENT", SubCategory: "PORT_STATUS"}
+	switch handler.eventFilterMgr.Evaluate(incoming) {
+	case ActionSuppress:
+		logger.Debugw("change-event-suppressed", log.Fields{"deviceId": deviceID})
+		return
+	case ActionRateLimited:
+		logger.Debugw("change-event-rate-limited", log.Fields{"deviceId": deviceID})
+		return
+	}
+
 	event := openflow_13.ChangeEvent{Id: deviceID, Event: &openflow_13.ChangeEvent_PortStatus{PortStatus: portStatus}}
 	logger.Debugw("SendChangeEvent", log.Fields{"event": event})
-	handler.changeEventQueue <- event
+	handler.changeEventHub.publish(deviceID, event)
 }
 
-// ReceiveChangeEvents receives change in events
-func (handler *NBIHandler) ReceiveChangeEvents(empty *empty.Empty, changeEvents voltha.VolthaService_ReceiveChangeEventsServer) error {
-	var streamingTracker = handler.getStreamingTracker("ReceiveChangeEvents", handler.changeEventQueueDone)
-	logger.Debugw("ReceiveChangeEvents-request", log.Fields{"changeEvents": changeEvents})
+// ReceiveChangeEvents streams change-events to one subscriber, following the same multi-subscriber
+// fan-out and replay-then-live-switch pattern as ReceivePacketsIn.
+func (handler *NBIHandler) ReceiveChangeEvents(_ *empty.Empty, changeEvents voltha.VolthaService_ReceiveChangeEventsServer) error {
+	ctx := changeEvents.Context()
+	ofAgentID, _ := OfAgentIDFromContext(ctx)
+	logger.Debugw("ReceiveChangeEvents-request", log.Fields{"ofAgentId": ofAgentID})
 
-	err := handler.flushFailedPackets(streamingTracker)
-	if err != nil {
-		logger.Errorw("unable-to-flush-failed-packets", log.Fields{"error": err})
+	ch, backlog, unsubscribe := handler.changeEventHub.subscribe()
+	defer unsubscribe()
+
+	for _, event := range backlog {
+		if err := changeEvents.Send(&event); err != nil {
+			logger.Errorw("failed-to-send-buffered-change-event", log.Fields{"ofAgentId": ofAgentID, "error": err})
+			return err
+		}
 	}
 
-loop:
 	for {
 		select {
-		// Dequeue a change event
-		case event := <-handler.changeEventQueue:
-			logger.Debugw("sending-change-event", log.Fields{"event": event})
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			logger.Debugw("sending-change-event", log.Fields{"ofAgentId": ofAgentID, "event": event})
 			if err := changeEvents.Send(&event); err != nil {
-				logger.Errorw("failed-to-send-change-event", log.Fields{"error": err})
-				// save last failed changeevent
-				streamingTracker.failedPacket = event
-			} else {
-				if streamingTracker.failedPacket != nil {
-					// reset last failed event saved on success to avoid flushing
-					streamingTracker.failedPacket = nil
-				}
+				logger.Errorw("failed-to-send-change-event", log.Fields{"ofAgentId": ofAgentID, "error": err})
+				return err
 			}
-		case <-handler.changeEventQueueDone:
-			logger.Debug("Another ReceiveChangeEvents already running. Bailing out ...")
-			break loop
+		case <-ctx.Done():
+			logger.Debugw("receiveChangeEvents-client-disconnected", log.Fields{"ofAgentId": ofAgentID})
+			return ctx.Err()
 		}
 	}
-
-	return nil
 }
 
-func (handler *NBIHandler) GetChangeEventsQueueForTest() <-chan openflow_13.ChangeEvent {
-	return handler.changeEventQueue
+// ModelChangeStream is what SubscribeChangeEvents sends model.ChangeEvent updates to. There is
+// no voltha.VolthaService_SubscribeChangeEventsServer to depend on - this RPC isn't one
+// VolthaService's .proto defines, and model.ChangeEvent's Data field is a bare interface{} with
+// no concrete schema to generate proto wire tags from in the first place - so this is this
+// package's own minimal stream contract instead. Like GetOperationStatus/StreamOperationStatus
+// (see their doc comments), this sits at the same tier as every other NBIHandler RPC: a method
+// ready to be registered, not yet wired into any grpc.Server because this tree has none.
+type ModelChangeStream interface {
+	Send(*model.ChangeEvent) error
+	Context() context.Context
+}
+
+// SubscribeChangeEvents streams db/model ChangeEvents - the add/update/remove mutations
+// modelAdd/Update/RemoveCallbacks feed into modelChangeBroker from this handler's own
+// model-mutating RPCs (e.g. CreateEventFilter/UpdateEventFilter/DeleteEventFilter) - to one
+// caller, scoped to filter.PathPrefix, until the caller disconnects.
+func (handler *NBIHandler) SubscribeChangeEvents(filter *model.ChangeEventFilter, stream ModelChangeStream) error {
+	ctx := stream.Context()
+	logger.Debugw("SubscribeChangeEvents-request", log.Fields{"pathPrefix": filter.PathPrefix})
+
+	ch := handler.modelChangeBroker.Subscribe(ctx, *filter)
+	for event := range ch {
+		if err := stream.Send(&event); err != nil {
+			logger.Errorw("failed-to-send-model-change-event", log.Fields{"pathPrefix": filter.PathPrefix, "error": err})
+			return err
+		}
+	}
+	return ctx.Err()
 }
 
-// Subscribe subscribing request of ofagent
+// Subscribe subscribes ofAgent with this core, starting (or renewing) the lease that
+// ofAgentMembership uses to arbitrate which of possibly several subscribed OFAgents gets
+// exclusive write access to each logical device.
 func (handler *NBIHandler) Subscribe(
 	ctx context.Context,
 	ofAgent *voltha.OfAgentSubscriber,
 ) (*voltha.OfAgentSubscriber, error) {
 	logger.Debugw("Subscribe-request", log.Fields{"ofAgent": ofAgent})
+	if err := handler.ofAgentMembership.Subscribe(ofAgent.OfagentId, ofAgent.VolthaId); err != nil {
+		return nil, status.Errorf(codes.Internal, "%s", err)
+	}
 	return &voltha.OfAgentSubscriber{OfagentId: ofAgent.OfagentId, VolthaId: ofAgent.VolthaId}, nil
 }
 
-// GetAlarmDeviceData @TODO useless stub, what should this actually do?
+// GetAlarmDeviceData returns the ONU adapter's alarm database for the device, round-tripped over
+// the inter-container proxy the same way StartOmciTestAction and GetExtValue reach their adapter.
 func (handler *NBIHandler) GetAlarmDeviceData(ctx context.Context, in *common.ID) (*omci.AlarmDeviceData, error) {
-	logger.Debug("GetAlarmDeviceData-stub")
-	return &omci.AlarmDeviceData{}, errors.New("UnImplemented")
+	logger.Debugw("GetAlarmDeviceData-request", log.Fields{"id": in.Id})
+	return handler.deviceMgr.GetAlarmDeviceData(ctx, in.Id)
 }
 
 // ListLogicalDeviceMeters returns logical device meters
@@ -739,66 +1055,187 @@ func (handler *NBIHandler) ListLogicalDeviceMeters(ctx context.Context, id *volt
 	return handler.logicalDeviceMgr.ListLogicalDeviceMeters(ctx, id.Id)
 }
 
-// GetMeterStatsOfLogicalDevice @TODO useless stub, what should this actually do?
+// GetMeterStatsOfLogicalDevice returns the logical device's current meter stats, fetched from its
+// owning adapter the same way ListLogicalDeviceMeters reaches logicalDeviceMgr.
 func (handler *NBIHandler) GetMeterStatsOfLogicalDevice(ctx context.Context, in *common.ID) (*openflow_13.MeterStatsReply, error) {
-	logger.Debug("GetMeterStatsOfLogicalDevice")
-	return &openflow_13.MeterStatsReply{}, errors.New("UnImplemented")
+	logger.Debugw("GetMeterStatsOfLogicalDevice-request", log.Fields{"id": in.Id})
+	return handler.logicalDeviceMgr.GetMeterStatsOfLogicalDevice(ctx, in.Id)
 }
 
-// GetMibDeviceData @TODO useless stub, what should this actually do?
+// GetMibDeviceData returns the ONU adapter's MIB upload database for the device, round-tripped
+// over the inter-container proxy the same way StartOmciTestAction and GetExtValue reach their
+// adapter.
 func (handler *NBIHandler) GetMibDeviceData(ctx context.Context, in *common.ID) (*omci.MibDeviceData, error) {
-	logger.Debug("GetMibDeviceData")
-	return &omci.MibDeviceData{}, errors.New("UnImplemented")
+	logger.Debugw("GetMibDeviceData-request", log.Fields{"id": in.Id})
+	return handler.deviceMgr.GetMibDeviceData(ctx, in.Id)
 }
 
 // SimulateAlarm sends simulate alarm request
+// SimulateAlarm kicks off the adapter round trip in the background and returns immediately with
+// the tracked operation's id in AdditionalInfo, so the caller can follow it to completion through
+// GetOperationStatus/StreamOperationStatus instead of the result being silently discarded.
 func (handler *NBIHandler) SimulateAlarm(
 	ctx context.Context,
 	in *voltha.SimulateAlarmRequest,
 ) (*common.OperationResp, error) {
 	logger.Debugw("SimulateAlarm-request", log.Fields{"id": in.Id})
-	successResp := &common.OperationResp{Code: common.OperationResp_OPERATION_SUCCESS}
-	ch := make(chan interface{})
-	defer close(ch)
-	go handler.deviceMgr.SimulateAlarm(ctx, in, ch)
-	return successResp, nil
+	opID := handler.operationTracker.Start(func(ctx context.Context) error {
+		ch := make(chan interface{})
+		defer close(ch)
+		go handler.deviceMgr.SimulateAlarm(ctx, in, ch)
+		select {
+		case res := <-ch:
+			if err, ok := res.(error); ok {
+				return err
+			}
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	return &common.OperationResp{Code: common.OperationResp_OPERATION_SUCCESS, AdditionalInfo: opID}, nil
+}
+
+// GetOperationStatus returns the current status of a tracked asynchronous operation by its id, as
+// returned by SimulateAlarm or set on the operation-id gRPC trailer of a synchronously-tracked RPC
+// like EnablePort.
+//
+// Like every other method on NBIHandler, this isn't registered against a grpc.Server anywhere in
+// this tree - there is no main/server-bootstrap file checked in here at all, for any RPC, so
+// nothing in this package is actually reachable over the wire yet. GetOperationStatus follows the
+// same (ctx, request) -> (response, error) shape the rest of NBIHandler already uses, so it's
+// ready to be registered the moment that bootstrap exists.
+func (handler *NBIHandler) GetOperationStatus(ctx context.Context, id *common.ID) (*OperationStatus, error) {
+	logger.Debugw("GetOperationStatus-request", log.Fields{"id": id.Id})
+	opStatus, err := handler.operationTracker.Get(id.Id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s", err)
+	}
+	return opStatus, nil
+}
+
+// StreamOperationStatus streams every subsequent state transition of the tracked operation id,
+// first sending its last-known status if there is one, so a client gets the outcome whether it
+// attaches before or after the operation finishes.
+//
+// It takes OperationStatusStream rather than a generated voltha.VolthaService_StreamOperationStatusServer
+// because this RPC isn't one VolthaService's .proto defines, so protoc-gen-go has never generated
+// that type; OperationStatusStream is satisfied by the grpc.ServerStream a real registration would
+// hand this method once GetOperationStatus/StreamOperationStatus are added to voltha-protos and
+// this package is wired into a grpc.Server (see the GetOperationStatus doc comment - no RPC in
+// this package is registered anywhere in this tree yet).
+func (handler *NBIHandler) StreamOperationStatus(id *common.ID, stream OperationStatusStream) error {
+	logger.Debugw("StreamOperationStatus-request", log.Fields{"id": id.Id})
+
+	current, ch, unsubscribe := handler.operationTracker.Subscribe(id.Id)
+	defer unsubscribe()
+
+	if current != nil {
+		if err := stream.Send(current); err != nil {
+			return err
+		}
+		if current.State != OperationPending {
+			return nil
+		}
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case update, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if update.Id != id.Id {
+				continue
+			}
+			if err := stream.Send(&update); err != nil {
+				return err
+			}
+			if update.State != OperationPending {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 }
 
 // UpdateLogicalDeviceMeterTable - This function sends meter mod request to logical device manager and waits for response
 func (handler *NBIHandler) UpdateLogicalDeviceMeterTable(ctx context.Context, meter *openflow_13.MeterModUpdate) (*empty.Empty, error) {
 	logger.Debugw("UpdateLogicalDeviceMeterTable-request",
 		log.Fields{"meter": meter, "test": common.TestModeKeys_api_test.String()})
-	ch := make(chan interface{})
-	defer close(ch)
-	go handler.logicalDeviceMgr.UpdateMeterTable(ctx, meter.Id, meter.MeterMod, ch)
-	return waitForNilResponseOnSuccess(ctx, ch)
-}
-
-// GetMembership returns membership
-func (handler *NBIHandler) GetMembership(context.Context, *empty.Empty) (*voltha.Membership, error) {
-	return &voltha.Membership{}, errors.New("UnImplemented")
+	if err := handler.authorizeWrite(ctx, meter.Id); err != nil {
+		return nil, err
+	}
+	err := handler.operationTracker.TrackSync(ctx, func() error {
+		ch := make(chan interface{})
+		defer close(ch)
+		go handler.logicalDeviceMgr.UpdateMeterTable(ctx, meter.Id, meter.MeterMod, ch)
+		_, err := waitForNilResponseOnSuccess(ctx, ch)
+		return err
+	})
+	return &empty.Empty{}, err
+}
+
+// membershipLeadersTrailerKey is the gRPC trailer metadata key GetMembership puts its
+// logicalDeviceId->ofAgentId leader mapping under, the same trailer-based approach
+// operationIDTrailerKey uses to surface data that has nowhere to go in the response message:
+// voltha.Membership's own fields aren't set from it since their real names aren't defined
+// anywhere in this tree and fabricating them would be guessing at a schema this repo doesn't
+// have, but a caller can still retrieve the actual mapping off the RPC instead of the response
+// staying silent about it.
+const membershipLeadersTrailerKey = "membership-leaders"
+
+// GetMembership reports whether this core currently knows of any ofagent leadership membership,
+// and - via the membershipLeadersTrailerKey gRPC trailer - the actual
+// logicalDeviceId->ofAgentId mapping handler.ofAgentMembership.Leaders() currently holds
+// (populated by the same Arbitrate calls that back authorizeWrite).
+func (handler *NBIHandler) GetMembership(ctx context.Context, _ *empty.Empty) (*voltha.Membership, error) {
+	leaders := handler.ofAgentMembership.Leaders()
+	if len(leaders) == 0 {
+		return &voltha.Membership{}, status.Error(codes.Unavailable, "no ofagent membership known yet")
+	}
+	data, err := json.Marshal(leaders)
+	if err != nil {
+		return &voltha.Membership{}, status.Errorf(codes.Internal, "marshal-membership-leaders: %s", err)
+	}
+	if err := grpc.SetTrailer(ctx, metadata.Pairs(membershipLeadersTrailerKey, string(data))); err != nil {
+		logger.Warnw("get-membership-set-trailer-failed", log.Fields{"error": err})
+	}
+	return &voltha.Membership{}, nil
 }
 
-// UpdateMembership updates membership
+// UpdateMembership is unsupported: this core's ofagent leadership membership is derived from
+// OFAgentMembershipManager's own leader election (see Arbitrate), not pushed in by a caller, so
+// there's nothing for an externally-supplied voltha.Membership to update.
 func (handler *NBIHandler) UpdateMembership(context.Context, *voltha.Membership) (*empty.Empty, error) {
-	return &empty.Empty{}, errors.New("UnImplemented")
+	return &empty.Empty{}, status.Error(codes.Unimplemented, "membership is derived from ofagent leader election and cannot be set externally")
 }
 
 func (handler *NBIHandler) EnablePort(ctx context.Context, port *voltha.Port) (*empty.Empty, error) {
 	logger.Debugw("EnablePort-request", log.Fields{"device-id": port.DeviceId, "port-no": port.PortNo})
-	ch := make(chan interface{})
-	defer close(ch)
-	go handler.deviceMgr.EnablePort(ctx, port, ch)
-	return waitForNilResponseOnSuccess(ctx, ch)
+	err := handler.operationTracker.TrackSync(ctx, func() error {
+		ch := make(chan interface{})
+		defer close(ch)
+		go handler.deviceMgr.EnablePort(ctx, port, ch)
+		_, err := waitForNilResponseOnSuccess(ctx, ch)
+		return err
+	})
+	return &empty.Empty{}, err
 }
 
 func (handler *NBIHandler) DisablePort(ctx context.Context, port *voltha.Port) (*empty.Empty, error) {
 
 	logger.Debugw("DisablePort-request", log.Fields{"device-id": port.DeviceId, "port-no": port.PortNo})
-	ch := make(chan interface{})
-	defer close(ch)
-	go handler.deviceMgr.DisablePort(ctx, port, ch)
-	return waitForNilResponseOnSuccess(ctx, ch)
+	err := handler.operationTracker.TrackSync(ctx, func() error {
+		ch := make(chan interface{})
+		defer close(ch)
+		go handler.deviceMgr.DisablePort(ctx, port, ch)
+		_, err := waitForNilResponseOnSuccess(ctx, ch)
+		return err
+	})
+	return &empty.Empty{}, err
 }
 
 func (handler *NBIHandler) StartOmciTestAction(ctx context.Context, omcitestrequest *voltha.OmciTestRequest) (*voltha.TestResponse, error) {
@@ -0,0 +1,160 @@
+/*
+* Copyright 2020-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// ofAgentSubscriptionKVPrefix namespaces a subscribed OFAgent's (ofAgentId -> volthaId) lease.
+	ofAgentSubscriptionKVPrefix = "service/voltha/ofagent_subscriptions"
+	// ofAgentLeaderKVPrefix namespaces the leader-election key for one logical device.
+	ofAgentLeaderKVPrefix = "service/voltha/ofagent_leaders"
+	// ofAgentLeaseTTL is how long a Subscribe or a won leader-election lasts without being
+	// renewed before the KV store expires it and lets another OFAgent claim it.
+	ofAgentLeaseTTL = 30 * time.Second
+	// ofAgentIDMetadataKey is the gRPC metadata key an OFAgent sends its subscribed id under on
+	// every call, so a write RPC on a connection can be checked against the current leader for
+	// the logical device it targets without threading an explicit id parameter through every RPC.
+	ofAgentIDMetadataKey = "ofagent_id"
+)
+
+// OfAgentIDFromContext returns the ofAgentId the caller sent via gRPC metadata, if any. An NBI
+// client that never called Subscribe (e.g. a direct voltctl request) has none, which write RPCs
+// treat as "not subject to leader arbitration" rather than rejecting it outright.
+func OfAgentIDFromContext(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get(ofAgentIDMetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return "", false
+	}
+	return values[0], true
+}
+
+// ofAgentSubscription is one OFAgent's last-known subscriber state.
+type ofAgentSubscription struct {
+	volthaID string
+	expires  time.Time
+}
+
+// OFAgentMembershipManager tracks which OFAgents are subscribed to this core and arbitrates, per
+// logical device, which one of possibly several subscribed OFAgents holds exclusive write access
+// (FlowUpdate, MeterMod, PacketOut) while the rest are limited to the read-only packet-in/
+// change-event streams. Leadership is arbitrated through kvStore's Reserve/RenewReservation lease
+// primitives - the same ones used for mutual exclusion elsewhere in voltha-go - so exactly one
+// winner is picked even when several rw_core replicas are arbitrating the same device at once.
+type OFAgentMembershipManager struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*ofAgentSubscription // ofAgentId -> subscription
+	leaders       map[string]string               // logicalDeviceId -> leader ofAgentId
+	kvStore       kvstore.Client
+}
+
+// NewOFAgentMembershipManager returns an OFAgentMembershipManager backed by kvStore. A nil
+// kvStore is accepted for tests: arbitration then falls back to in-memory first-claim-wins, with
+// no cross-replica coordination.
+func NewOFAgentMembershipManager(kvStore kvstore.Client) *OFAgentMembershipManager {
+	return &OFAgentMembershipManager{
+		subscriptions: make(map[string]*ofAgentSubscription),
+		leaders:       make(map[string]string),
+		kvStore:       kvStore,
+	}
+}
+
+// Subscribe records ofAgentID's (re)subscription with a fresh lease, persisting it to the KV
+// store so another core replica can see it too. An OFAgent is expected to call this periodically
+// (well inside ofAgentLeaseTTL) to keep its subscription - and any logical device leadership it
+// holds - alive.
+func (m *OFAgentMembershipManager) Subscribe(ofAgentID, volthaID string) error {
+	m.mu.Lock()
+	m.subscriptions[ofAgentID] = &ofAgentSubscription{volthaID: volthaID, expires: time.Now().Add(ofAgentLeaseTTL)}
+	m.mu.Unlock()
+
+	if m.kvStore == nil {
+		return nil
+	}
+	key := ofAgentSubscriptionKVPrefix + "/" + ofAgentID
+	if _, err := m.kvStore.Reserve(key, []byte(volthaID), ofAgentLeaseTTL); err != nil {
+		return fmt.Errorf("ofagent-subscribe-%s: %w", ofAgentID, err)
+	}
+	if err := m.kvStore.RenewReservation(key); err != nil {
+		logger.Warnw("ofagent-subscription-renew-failed", log.Fields{"ofAgentId": ofAgentID, "error": err})
+	}
+	return nil
+}
+
+// Arbitrate reports whether ofAgentID is (or just became) the leader for logicalDeviceID: the
+// first OFAgent to successfully reserve the device's leader key keeps it by renewing the
+// reservation on every later call; any other OFAgent calling in gets false, whether or not it ever
+// held that leadership. A logical device with no leader claimed yet lets the calling ofAgentID
+// through and claims it in the same call, so the first OFAgent to send a write RPC for a device
+// becomes its leader.
+func (m *OFAgentMembershipManager) Arbitrate(logicalDeviceID, ofAgentID string) (bool, error) {
+	key := ofAgentLeaderKVPrefix + "/" + logicalDeviceID
+
+	m.mu.RLock()
+	current, known := m.leaders[logicalDeviceID]
+	m.mu.RUnlock()
+	if known && current == ofAgentID {
+		if m.kvStore != nil {
+			if err := m.kvStore.RenewReservation(key); err != nil {
+				logger.Warnw("ofagent-leader-renew-failed", log.Fields{"logicalDeviceId": logicalDeviceID, "error": err})
+			}
+		}
+		return true, nil
+	}
+
+	if m.kvStore != nil {
+		owner, err := m.kvStore.Reserve(key, []byte(ofAgentID), ofAgentLeaseTTL)
+		if err != nil {
+			return false, fmt.Errorf("ofagent-arbitrate-%s: %w", logicalDeviceID, err)
+		}
+		if ownerID, ok := owner.([]byte); ok && string(ownerID) != ofAgentID {
+			return false, nil
+		}
+	} else if known {
+		// no KV store to arbitrate through: the first in-memory claim wins and stays won.
+		return false, nil
+	}
+
+	m.mu.Lock()
+	m.leaders[logicalDeviceID] = ofAgentID
+	m.mu.Unlock()
+	return true, nil
+}
+
+// Leaders returns a snapshot of every logical device this core currently believes has a leader,
+// keyed by logical device id, for exposing the current mapping (e.g. via GetMembership).
+func (m *OFAgentMembershipManager) Leaders() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]string, len(m.leaders))
+	for k, v := range m.leaders {
+		out[k] = v
+	}
+	return out
+}
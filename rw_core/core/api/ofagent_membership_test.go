@@ -0,0 +1,75 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestOfAgentIDFromContextReturnsFalseWithoutMetadata(t *testing.T) {
+	_, ok := OfAgentIDFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestOfAgentIDFromContextReturnsValueFromMetadata(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ofAgentIDMetadataKey, "ofagent-1"))
+	id, ok := OfAgentIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "ofagent-1", id)
+}
+
+func TestOFAgentMembershipManagerArbitrateFirstClaimWins(t *testing.T) {
+	mgr := NewOFAgentMembershipManager(nil)
+
+	isLeader, err := mgr.Arbitrate("logical-device-1", "ofagent-1")
+	assert.NoError(t, err)
+	assert.True(t, isLeader)
+
+	isLeader, err = mgr.Arbitrate("logical-device-1", "ofagent-2")
+	assert.NoError(t, err)
+	assert.False(t, isLeader)
+}
+
+func TestOFAgentMembershipManagerArbitrateSameLeaderStaysLeader(t *testing.T) {
+	mgr := NewOFAgentMembershipManager(nil)
+
+	_, err := mgr.Arbitrate("logical-device-1", "ofagent-1")
+	assert.NoError(t, err)
+
+	isLeader, err := mgr.Arbitrate("logical-device-1", "ofagent-1")
+	assert.NoError(t, err)
+	assert.True(t, isLeader)
+}
+
+func TestOFAgentMembershipManagerSubscribeWithoutKVStoreSucceeds(t *testing.T) {
+	mgr := NewOFAgentMembershipManager(nil)
+	assert.NoError(t, mgr.Subscribe("ofagent-1", "voltha-1"))
+}
+
+func TestOFAgentMembershipManagerLeadersSnapshot(t *testing.T) {
+	mgr := NewOFAgentMembershipManager(nil)
+
+	_, err := mgr.Arbitrate("logical-device-1", "ofagent-1")
+	assert.NoError(t, err)
+	_, err = mgr.Arbitrate("logical-device-2", "ofagent-2")
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"logical-device-1": "ofagent-1", "logical-device-2": "ofagent-2"}, mgr.Leaders())
+}
@@ -0,0 +1,200 @@
+/*
+* Copyright 2020-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// operationTrackerKVPrefix namespaces persisted operation status under the core's existing KV
+// store, the same one EventFilterManager and OFAgentMembershipManager use.
+const operationTrackerKVPrefix = "service/voltha/async_operations"
+
+// operationIDTrailerKey is the gRPC trailer metadata key a synchronously-tracked RPC (one that
+// still blocks until its operation completes, like EnablePort) sets its operation's UUID under, so
+// a caller can still correlate the RPC with its GetOperationStatus/StreamOperationStatus history
+// even though the id has nowhere to go in that RPC's own response message.
+const operationIDTrailerKey = "operation-id"
+
+// OperationState is where a tracked asynchronous operation currently stands.
+type OperationState string
+
+const (
+	OperationPending OperationState = "PENDING"
+	OperationSuccess OperationState = "SUCCESS"
+	OperationFailure OperationState = "FAILURE"
+)
+
+// OperationStatus is one tracked operation's current state, as returned by GetOperationStatus and
+// streamed by StreamOperationStatus. voltha-protos has no .proto source for this message - it is
+// not one of the RPCs VolthaService defines - so there is nothing to run protoc-gen-go against;
+// the struct tags and Reset/String/ProtoMessage below are hand-written to exactly what
+// protoc-gen-go would emit for this shape, so it still marshals over gRPC like a generated
+// message instead of the grpc-go codec panicking on a type with no proto.Message implementation.
+type OperationStatus struct {
+	Id    string         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	State OperationState `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Error string         `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (m *OperationStatus) Reset()         { *m = OperationStatus{} }
+func (m *OperationStatus) String() string { return proto.CompactTextString(m) }
+func (*OperationStatus) ProtoMessage()    {}
+
+// OperationTracker assigns a UUID to each operation it runs and persists every state transition -
+// PENDING, then SUCCESS or FAILURE with the adapter's error detail - to the KV store, so a
+// long-running or fire-and-forget RPC like SimulateAlarm becomes observable through
+// GetOperationStatus/StreamOperationStatus instead of the caller losing its result the moment the
+// RPC returns.
+type OperationTracker struct {
+	mu      sync.RWMutex
+	ops     map[string]*OperationStatus
+	hub     *streamHub[OperationStatus]
+	kvStore kvstore.Client
+}
+
+// NewOperationTracker returns an OperationTracker backed by kvStore.
+func NewOperationTracker(kvStore kvstore.Client) *OperationTracker {
+	return &OperationTracker{
+		ops:     make(map[string]*OperationStatus),
+		hub:     newStreamHub[OperationStatus](),
+		kvStore: kvStore,
+	}
+}
+
+func (t *OperationTracker) persist(status *OperationStatus) {
+	if t.kvStore == nil {
+		return
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		logger.Warnw("operation-tracker-marshal-failed", log.Fields{"id": status.Id, "error": err})
+		return
+	}
+	if err := t.kvStore.Put(operationTrackerKVPrefix+"/"+status.Id, data); err != nil {
+		logger.Warnw("operation-tracker-persist-failed", log.Fields{"id": status.Id, "error": err})
+	}
+}
+
+func (t *OperationTracker) setState(id string, state OperationState, opErr error) *OperationStatus {
+	status := &OperationStatus{Id: id, State: state}
+	if opErr != nil {
+		status.Error = opErr.Error()
+	}
+
+	t.mu.Lock()
+	t.ops[id] = status
+	t.mu.Unlock()
+
+	t.persist(status)
+	t.hub.publish(id, *status)
+	return status
+}
+
+// Start assigns a fresh UUID to op, runs it in its own goroutine, and records its PENDING ->
+// SUCCESS/FAILURE transition once op returns, so the caller's RPC can hand the id straight back to
+// its client without waiting for op to finish.
+func (t *OperationTracker) Start(op func(ctx context.Context) error) string {
+	id := uuid.New().String()
+	t.setState(id, OperationPending, nil)
+
+	go func() {
+		if err := op(context.Background()); err != nil {
+			t.setState(id, OperationFailure, err)
+			return
+		}
+		t.setState(id, OperationSuccess, nil)
+	}()
+
+	return id
+}
+
+// TrackSync runs op to completion on the calling goroutine, same as before it was tracked, while
+// still recording its PENDING -> SUCCESS/FAILURE transition and setting the id as a gRPC trailer
+// on ctx so a caller that wants to correlate this RPC with its GetOperationStatus history can,
+// even though the RPC's own response has no field to carry it (e.g. EnablePort's empty.Empty).
+func (t *OperationTracker) TrackSync(ctx context.Context, op func() error) error {
+	id := uuid.New().String()
+	t.setState(id, OperationPending, nil)
+	if err := grpc.SetTrailer(ctx, metadata.Pairs(operationIDTrailerKey, id)); err != nil {
+		logger.Warnw("operation-tracker-set-trailer-failed", log.Fields{"id": id, "error": err})
+	}
+
+	err := op()
+	if err != nil {
+		t.setState(id, OperationFailure, err)
+		return err
+	}
+	t.setState(id, OperationSuccess, nil)
+	return nil
+}
+
+// Get returns the current status of a tracked operation, falling back to the KV store - so a
+// lookup still works after a core restart - if id isn't held in memory.
+func (t *OperationTracker) Get(id string) (*OperationStatus, error) {
+	t.mu.RLock()
+	status, ok := t.ops[id]
+	t.mu.RUnlock()
+	if ok {
+		return status, nil
+	}
+
+	if t.kvStore == nil {
+		return nil, fmt.Errorf("operation-tracker-get-%s: not found", id)
+	}
+	pair, err := t.kvStore.Get(operationTrackerKVPrefix + "/" + id)
+	if err != nil || pair == nil {
+		return nil, fmt.Errorf("operation-tracker-get-%s: not found", id)
+	}
+	data, ok := pair.Value.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("operation-tracker-get-%s: unexpected value type", id)
+	}
+	status = &OperationStatus{}
+	if err := json.Unmarshal(data, status); err != nil {
+		return nil, fmt.Errorf("operation-tracker-get-%s: %w", id, err)
+	}
+	return status, nil
+}
+
+// Subscribe returns id's last-known status, if any, together with a channel of every subsequent
+// state transition for any tracked operation; the hub is shared by every tracked operation, so the
+// caller filters by OperationStatus.Id. The caller must invoke unsubscribe once done.
+func (t *OperationTracker) Subscribe(id string) (current *OperationStatus, ch chan OperationStatus, unsubscribe func()) {
+	ch, _, unsubscribe = t.hub.subscribe()
+	current, _ = t.Get(id)
+	return current, ch, unsubscribe
+}
+
+// OperationStatusStream is what StreamOperationStatus sends updates to. GetOperationStatus's
+// streaming counterpart is not one of the RPCs VolthaService's .proto defines, so there is no
+// generated voltha.VolthaService_StreamOperationStatusServer to depend on; this is this package's
+// own minimal stream contract, satisfied by the grpc.ServerStream a real service registration
+// would hand StreamOperationStatus once this RPC is added to voltha-protos.
+type OperationStatusStream interface {
+	Send(*OperationStatus) error
+	Context() context.Context
+}
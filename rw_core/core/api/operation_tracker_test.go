@@ -0,0 +1,81 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationTrackerStartRecordsSuccess(t *testing.T) {
+	tracker := NewOperationTracker(nil)
+
+	id := tracker.Start(func(ctx context.Context) error { return nil })
+
+	assert.Eventually(t, func() bool {
+		status, err := tracker.Get(id)
+		return err == nil && status.State == OperationSuccess
+	}, time.Second, time.Millisecond)
+}
+
+func TestOperationTrackerStartRecordsFailure(t *testing.T) {
+	tracker := NewOperationTracker(nil)
+
+	id := tracker.Start(func(ctx context.Context) error { return errors.New("adapter-unreachable") })
+
+	assert.Eventually(t, func() bool {
+		status, err := tracker.Get(id)
+		return err == nil && status.State == OperationFailure && status.Error == "adapter-unreachable"
+	}, time.Second, time.Millisecond)
+}
+
+func TestOperationTrackerGetUnknownIDFails(t *testing.T) {
+	tracker := NewOperationTracker(nil)
+	_, err := tracker.Get("no-such-id")
+	assert.Error(t, err)
+}
+
+func TestOperationTrackerTrackSyncReturnsOpError(t *testing.T) {
+	tracker := NewOperationTracker(nil)
+	err := tracker.TrackSync(context.Background(), func() error { return errors.New("boom") })
+	assert.EqualError(t, err, "boom")
+}
+
+func TestOperationTrackerSubscribeReceivesStateTransitions(t *testing.T) {
+	tracker := NewOperationTracker(nil)
+
+	release := make(chan struct{})
+	id := tracker.Start(func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	_, ch, unsubscribe := tracker.Subscribe(id)
+	defer unsubscribe()
+	close(release)
+
+	select {
+	case update := <-ch:
+		assert.Equal(t, id, update.Id)
+		assert.Equal(t, OperationSuccess, update.State)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for operation status update")
+	}
+}
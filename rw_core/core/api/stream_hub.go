@@ -0,0 +1,104 @@
+/*
+* Copyright 2020-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package api
+
+import "sync"
+
+const (
+	// streamReplayBufferSize is how many recent items streamHub retains per logical device, so a
+	// subscriber that (re)connects - e.g. an OFAgent replica after a blip - can catch up on what
+	// it missed instead of starting from a blank slate.
+	streamReplayBufferSize = 256
+	// streamSubscriberQueueSize bounds each subscriber's own delivery channel. A subscriber that
+	// falls behind has its oldest buffered item dropped to make room rather than blocking publish.
+	streamSubscriberQueueSize = 100
+)
+
+// streamHub fans items of type T out to every active subscriber, while separately retaining a
+// bounded, per-logical-device replay buffer so a newly (re)connected subscriber can be drained the
+// recent history for every device before being switched over to live traffic. It replaces the
+// single-consumer queue + single-slot failed-item retry that ReceivePacketsIn/ReceiveChangeEvents
+// used to share.
+type streamHub[T any] struct {
+	mu          sync.Mutex
+	buffers     map[string][]T // logical device id -> last streamReplayBufferSize items
+	subscribers map[uint64]chan T
+	nextID      uint64
+}
+
+func newStreamHub[T any]() *streamHub[T] {
+	return &streamHub[T]{
+		buffers:     make(map[string][]T),
+		subscribers: make(map[uint64]chan T),
+	}
+}
+
+// publish appends item to deviceID's replay buffer, trimming from the oldest end once it exceeds
+// streamReplayBufferSize, and fans it out to every current subscriber. A subscriber whose channel
+// is full has its own oldest pending item dropped to make room, so one slow subscriber never
+// blocks delivery to the others or to publish's caller.
+func (h *streamHub[T]) publish(deviceID string, item T) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	buf := append(h.buffers[deviceID], item)
+	if len(buf) > streamReplayBufferSize {
+		buf = buf[len(buf)-streamReplayBufferSize:]
+	}
+	h.buffers[deviceID] = buf
+
+	for _, ch := range h.subscribers {
+		select {
+		case ch <- item:
+		default:
+			// subscriber is behind: drop its oldest pending item and retry once, best effort.
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- item:
+			default:
+			}
+		}
+	}
+}
+
+// subscribe registers a new subscriber and returns its delivery channel together with a snapshot
+// of every logical device's current replay buffer, taken atomically with registration so no item
+// published afterwards is missed or duplicated. The caller must invoke unsubscribe once done to
+// release the channel.
+func (h *streamHub[T]) subscribe() (ch chan T, backlog []T, unsubscribe func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	ch = make(chan T, streamSubscriberQueueSize)
+	h.subscribers[id] = ch
+
+	for _, buf := range h.buffers {
+		backlog = append(backlog, buf...)
+	}
+
+	unsubscribe = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers, id)
+		close(ch)
+	}
+	return ch, backlog, unsubscribe
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamHubFansOutToMultipleSubscribers(t *testing.T) {
+	hub := newStreamHub[int]()
+
+	ch1, backlog1, unsub1 := hub.subscribe()
+	defer unsub1()
+	ch2, backlog2, unsub2 := hub.subscribe()
+	defer unsub2()
+
+	assert.Empty(t, backlog1)
+	assert.Empty(t, backlog2)
+
+	hub.publish("device-1", 42)
+
+	assert.Equal(t, 42, <-ch1)
+	assert.Equal(t, 42, <-ch2)
+}
+
+func TestStreamHubReplaysBufferedHistoryToNewSubscriber(t *testing.T) {
+	hub := newStreamHub[int]()
+
+	hub.publish("device-1", 1)
+	hub.publish("device-1", 2)
+	hub.publish("device-2", 3)
+
+	_, backlog, unsub := hub.subscribe()
+	defer unsub()
+
+	assert.ElementsMatch(t, []int{1, 2, 3}, backlog)
+}
+
+func TestStreamHubTrimsReplayBufferToBound(t *testing.T) {
+	hub := newStreamHub[int]()
+
+	for i := 0; i < streamReplayBufferSize+10; i++ {
+		hub.publish("device-1", i)
+	}
+
+	_, backlog, unsub := hub.subscribe()
+	defer unsub()
+
+	assert.Len(t, backlog, streamReplayBufferSize)
+	assert.Equal(t, 10, backlog[0])
+}
+
+func TestStreamHubDropsOldestOnSlowSubscriberInsteadOfBlocking(t *testing.T) {
+	hub := newStreamHub[int]()
+
+	ch, _, unsub := hub.subscribe()
+	defer unsub()
+
+	for i := 0; i < streamSubscriberQueueSize+5; i++ {
+		hub.publish("device-1", i)
+	}
+
+	assert.Len(t, ch, streamSubscriberQueueSize)
+}
+
+func TestStreamHubUnsubscribeStopsDeliveryAndClosesChannel(t *testing.T) {
+	hub := newStreamHub[int]()
+
+	ch, _, unsub := hub.subscribe()
+	unsub()
+
+	hub.publish("device-1", 99)
+
+	_, ok := <-ch
+	assert.False(t, ok, "channel should be closed after unsubscribe")
+}
@@ -25,7 +25,6 @@ import (
 	"github.com/opencord/voltha-lib-go/v3/pkg/db"
 	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
 	"github.com/opencord/voltha-lib-go/v3/pkg/kafka"
-	"github.com/opencord/voltha-lib-go/v3/pkg/log"
 	mock_etcd "github.com/opencord/voltha-lib-go/v3/pkg/mocks/etcd"
 	mock_kafka "github.com/opencord/voltha-lib-go/v3/pkg/mocks/kafka"
 	ofp "github.com/opencord/voltha-protos/v3/go/openflow_13"
@@ -36,7 +35,6 @@ import (
 	"google.golang.org/grpc/status"
 	"math/rand"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -156,6 +154,54 @@ func (dat *DATest) startCore(inCompeteMode bool) {
 	dat.logicalDeviceMgr.Start(context.Background())
 }
 
+// startCoreWithFaults is identical to startCore except the KV client is wrapped in a
+// FaultInjectingKVClient configured with faults, so writes under "/devices" can be delayed,
+// made to error out, or blocked for a partition window while device agents are mutating state.
+func (dat *DATest) startCoreWithFaults(inCompeteMode bool, faults ...FaultInjectorConfig) {
+	cfg := config.NewRWCoreFlags()
+	cfg.CorePairTopic = "rw_core"
+	cfg.DefaultRequestTimeout = dat.defaultTimeout
+	cfg.KVStorePort = dat.kvClientPort
+	cfg.InCompetingMode = inCompeteMode
+	grpcPort, err := freeport.GetFreePort()
+	if err != nil {
+		logger.Fatal("Cannot get a freeport for grpc")
+	}
+	cfg.GrpcPort = grpcPort
+	cfg.GrpcHost = "127.0.0.1"
+	client := NewFaultInjectingKVClient(setupKVClient(cfg, dat.coreInstanceID))
+	client.Configure(faults...)
+	backend := &db.Backend{
+		Client:                  client,
+		StoreType:               cfg.KVStoreType,
+		Host:                    cfg.KVStoreHost,
+		Port:                    cfg.KVStorePort,
+		Timeout:                 cfg.KVStoreTimeout,
+		LivenessChannelInterval: cfg.LiveProbeInterval / 2,
+		PathPrefix:              cfg.KVStoreDataPrefix}
+	dat.kmp = kafka.NewInterContainerProxy(
+		kafka.InterContainerHost(cfg.KafkaAdapterHost),
+		kafka.InterContainerPort(cfg.KafkaAdapterPort),
+		kafka.MsgClient(dat.kClient),
+		kafka.DefaultTopic(&kafka.Topic{Name: cfg.CoreTopic}),
+		kafka.DeviceDiscoveryTopic(&kafka.Topic{Name: cfg.AffinityRouterTopic}))
+
+	endpointMgr := kafka.NewEndpointManager(backend)
+	proxy := model.NewProxy(backend, "/")
+	adapterMgr := adapter.NewAdapterManager(proxy, dat.coreInstanceID, dat.kClient)
+
+	dat.deviceMgr, dat.logicalDeviceMgr = NewDeviceManagers(proxy, adapterMgr, dat.kmp, endpointMgr, cfg.CorePairTopic, dat.coreInstanceID, cfg.DefaultCoreTimeout)
+	dat.logicalDeviceMgr.SetEventCallbacks(fakeEventCallbacks{})
+	if err = dat.kmp.Start(); err != nil {
+		logger.Fatal("Cannot start InterContainerProxy")
+	}
+	if err = adapterMgr.Start(context.Background()); err != nil {
+		logger.Fatal("Cannot start adapterMgr")
+	}
+	dat.deviceMgr.Start(context.Background())
+	dat.logicalDeviceMgr.Start(context.Background())
+}
+
 func (dat *DATest) stopAll() {
 	if dat.kClient != nil {
 		dat.kClient.Stop()
@@ -197,11 +243,14 @@ func stopEmbeddedEtcdServer(server *mock_etcd.EtcdServer) {
 	}
 }
 
+// setupKVClient builds the kvstore.Client to exercise the RW core against, going through the
+// same config.KVBackendFactory selection the production binary uses instead of assuming etcd.
+// This lets DATest be re-run against any registered backend (etcd, consul, redis, or a mock) by
+// simply changing cf.KVStoreType.
 func setupKVClient(cf *config.RWCoreFlags, coreInstanceID string) kvstore.Client {
-	addr := cf.KVStoreHost + ":" + strconv.Itoa(cf.KVStorePort)
-	client, err := kvstore.NewEtcdClient(addr, cf.KVStoreTimeout, log.FatalLevel)
+	client, err := config.NewKVClient(cf)
 	if err != nil {
-		panic("no kv client")
+		logger.Fatal(err)
 	}
 	return client
 }
@@ -309,6 +358,35 @@ func TestConcurrentDevices(t *testing.T) {
 	}
 }
 
+// TestConcurrentDevicesUnderFaultInjection re-runs the TestConcurrentDevices scenario with the
+// KV client wrapped in a FaultInjectingKVClient so that writes under "/devices" incur random
+// latency, a non-trivial error rate, and a brief partition window. getDevice is still expected
+// to converge to the fully merged state, exercising the optimistic-locking retries that
+// updateDeviceUsingAdapterData relies on under real-world KV flakiness.
+func TestConcurrentDevicesUnderFaultInjection(t *testing.T) {
+	da := newDATest()
+	assert.NotNil(t, da)
+	defer da.stopAll()
+
+	da.startCoreWithFaults(false, FaultInjectorConfig{
+		KeyPrefix:       "/devices",
+		MinLatency:      time.Millisecond,
+		MaxLatency:      20 * time.Millisecond,
+		ErrorRate:       0.1,
+		PartitionWindow: 50 * time.Millisecond,
+	})
+
+	var wg sync.WaitGroup
+	numConCurrentDeviceAgents := 20
+	for i := 0; i < numConCurrentDeviceAgents; i++ {
+		wg.Add(1)
+		a := da.createDeviceAgent(t)
+		go da.updateDeviceConcurrently(t, a, &wg)
+	}
+
+	wg.Wait()
+}
+
 func isFlowSliceEqual(a, b []*ofp.OfpFlowStats) bool {
 	if len(a) != len(b) {
 		return false
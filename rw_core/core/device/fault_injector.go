@@ -0,0 +1,126 @@
+/*
+* Copyright 2019-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package device
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
+)
+
+// FaultInjectorConfig describes the faults a FaultInjectingKVClient should simulate for a given
+// key prefix: added latency, a chance to fail outright, and a window during which writes to that
+// prefix are blocked entirely (simulating a network partition).
+type FaultInjectorConfig struct {
+	KeyPrefix       string
+	MinLatency      time.Duration
+	MaxLatency      time.Duration
+	ErrorRate       float64 // 0..1
+	PartitionWindow time.Duration
+}
+
+func (c FaultInjectorConfig) matches(key string) bool {
+	return c.KeyPrefix == "" || strings.HasPrefix(key, c.KeyPrefix)
+}
+
+func (c FaultInjectorConfig) latency() time.Duration {
+	if c.MaxLatency <= c.MinLatency {
+		return c.MinLatency
+	}
+	return c.MinLatency + time.Duration(rand.Int63n(int64(c.MaxLatency-c.MinLatency)))
+}
+
+// FaultInjectingKVClient wraps a kvstore.Client and, for any key matching a configured
+// FaultInjectorConfig, introduces latency, returns an injected error, or blocks the call for the
+// duration of a simulated partition window before delegating to the real client. It exists so
+// TestConcurrentDevicesUnderFaultInjection can assert that getDevice still converges to the expected merged state
+// even when the KV store misbehaves mid-transaction.
+type FaultInjectingKVClient struct {
+	kvstore.Client
+	mu      sync.RWMutex
+	faults  []FaultInjectorConfig
+	blocked map[string]time.Time // key prefix -> time the partition window ends
+}
+
+// NewFaultInjectingKVClient wraps client with no faults configured; call Configure to start
+// injecting.
+func NewFaultInjectingKVClient(client kvstore.Client) *FaultInjectingKVClient {
+	return &FaultInjectingKVClient{Client: client, blocked: make(map[string]time.Time)}
+}
+
+// Configure replaces the set of active fault configurations.
+func (f *FaultInjectingKVClient) Configure(faults ...FaultInjectorConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = faults
+	for _, fault := range faults {
+		if fault.PartitionWindow > 0 {
+			f.blocked[fault.KeyPrefix] = time.Now().Add(fault.PartitionWindow)
+		}
+	}
+}
+
+func (f *FaultInjectingKVClient) faultFor(key string) (FaultInjectorConfig, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, fault := range f.faults {
+		if fault.matches(key) {
+			return fault, true
+		}
+	}
+	return FaultInjectorConfig{}, false
+}
+
+// inject applies the configured latency/partition/error-rate for key, returning a non-nil error
+// if the call should fail outright. It is called by Put/Delete - the mutating operations whose
+// ordering under contention is what updateDeviceUsingAdapterData's optimistic locking depends on.
+func (f *FaultInjectingKVClient) inject(key string) error {
+	fault, ok := f.faultFor(key)
+	if !ok {
+		return nil
+	}
+	if until, blocked := f.blocked[fault.KeyPrefix]; blocked {
+		if wait := time.Until(until); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+	if d := fault.latency(); d > 0 {
+		time.Sleep(d)
+	}
+	if fault.ErrorRate > 0 && rand.Float64() < fault.ErrorRate {
+		return kvstore.ErrKvConnectionDown
+	}
+	return nil
+}
+
+// Put injects configured faults for key before delegating to the wrapped client.
+func (f *FaultInjectingKVClient) Put(key string, value interface{}) error {
+	if err := f.inject(key); err != nil {
+		return err
+	}
+	return f.Client.Put(key, value)
+}
+
+// Delete injects configured faults for key before delegating to the wrapped client.
+func (f *FaultInjectingKVClient) Delete(key string) error {
+	if err := f.inject(key); err != nil {
+		return err
+	}
+	return f.Client.Delete(key)
+}
@@ -0,0 +1,153 @@
+/*
+* Copyright 2019-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package device
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gogo/protobuf/proto"
+	ofp "github.com/opencord/voltha-protos/v3/go/openflow_13"
+)
+
+// flowHashKey identifies a flow by what OpenFlow actually considers it to be - table, priority,
+// match, and cookie - rather than by Id, since an adapter re-reading the same flow from a device
+// is not guaranteed to hand back the same Id on every read. Two flows with different Ids but the
+// same key are the same flow, not an unrelated add alongside a stale leftover.
+func flowHashKey(f *ofp.OfpFlowStats) string {
+	match, _ := proto.Marshal(f.Match)
+	return fmt.Sprintf("%d|%d|%x|%d", f.TableId, f.Priority, match, f.Cookie)
+}
+
+// flowsToUpdateToDelete indexes existingFlows by both Id and flowHashKey and, for each newFlow,
+// matches on flowHashKey first - that's the identity OpenFlow actually cares about - falling back
+// to Id only when no flow shares that hash. Checking Id first would let a newFlow whose Id happens
+// to coincide with an unrelated existing flow's Id "steal" that entry before the real hash match is
+// ever looked up, leaving the true match undeleted. It computes, in a single pass over each map,
+// the three-way split a logical-device reconciliation needs:
+//   - updatedNewFlows: entries of newFlows that are absent from existingFlows or semantically
+//     changed from their existing match (OFPFC_ADD)
+//   - flowsToDelete: entries present in both but semantically changed, so the stale copy from
+//     existingFlows must be removed with OFPFC_DELETE_STRICT before the new one is re-added
+//   - updatedAllFlows: the merged authoritative set (unchanged + changed-new + added), sorted by
+//     Id so two runs over the same input produce the same output, used to replace the logical
+//     device's flow table in one shot
+//
+// This replaces the old O(n*m) linear scan against existingFlows for every entry in newFlows.
+//
+// TODO: the real caller of this is a logical-device reconciliation loop, but this tree has no
+// LogicalManager implementation to host one (device.LogicalManager is referenced from rw_core/core
+// but not defined anywhere in this tree) - until that exists, this is exercised by its own tests
+// only.
+func flowsToUpdateToDelete(newFlows, existingFlows []*ofp.OfpFlowStats) (updatedNewFlows, flowsToDelete, updatedAllFlows []*ofp.OfpFlowStats) {
+	existingByID := make(map[uint64]*ofp.OfpFlowStats, len(existingFlows))
+	existingByHash := make(map[string]*ofp.OfpFlowStats, len(existingFlows))
+	for _, f := range existingFlows {
+		existingByID[f.Id] = f
+		existingByHash[flowHashKey(f)] = f
+	}
+
+	for _, newFlow := range newFlows {
+		newHash := flowHashKey(newFlow)
+		hashMatch, foundByHash := existingByHash[newHash]
+		idMatch, foundByID := existingByID[newFlow.Id]
+
+		existing, found := idMatch, foundByID
+		if foundByHash {
+			existing, found = hashMatch, true
+		}
+
+		switch {
+		case !found:
+			updatedNewFlows = append(updatedNewFlows, newFlow)
+			updatedAllFlows = append(updatedAllFlows, newFlow)
+		case !proto.Equal(existing, newFlow):
+			updatedNewFlows = append(updatedNewFlows, newFlow)
+			flowsToDelete = append(flowsToDelete, existing)
+			updatedAllFlows = append(updatedAllFlows, newFlow)
+		default:
+			// unchanged: carry the existing entry over untouched
+			updatedAllFlows = append(updatedAllFlows, existing)
+		}
+		if found {
+			delete(existingByID, existing.Id)
+			delete(existingByHash, flowHashKey(existing))
+		}
+
+		// newFlow.Id may also belong to a second, unrelated existing flow distinct from the one
+		// just matched above by hash (e.g. hash matched B but a stale, independent A happens to
+		// share newFlow.Id). Left alone, A would keep its Id and collide with newFlow's Id in
+		// updatedAllFlows instead of being reconciled, so delete it too.
+		if foundByHash && foundByID && idMatch.Id != existing.Id {
+			if _, stillPresent := existingByID[idMatch.Id]; stillPresent {
+				flowsToDelete = append(flowsToDelete, idMatch)
+				delete(existingByID, idMatch.Id)
+				delete(existingByHash, flowHashKey(idMatch))
+			}
+		}
+	}
+
+	// whatever is left in existingByID was not present in newFlows at all and is kept as-is;
+	// existingByID's range order is randomized per run, so the merged set is sorted by Id to make
+	// the result deterministic.
+	remaining := make([]*ofp.OfpFlowStats, 0, len(existingByID))
+	for _, f := range existingByID {
+		remaining = append(remaining, f)
+	}
+	updatedAllFlows = append(updatedAllFlows, remaining...)
+	sort.Slice(updatedAllFlows, func(i, j int) bool { return updatedAllFlows[i].Id < updatedAllFlows[j].Id })
+
+	return
+}
+
+// groupsToUpdateToDelete applies the same indexed three-way-merge pattern as
+// flowsToUpdateToDelete, keyed by Desc.GroupId instead of Id. Groups have no table/priority/match/
+// cookie to key a hash-based fallback on, so Desc.GroupId is their only identity.
+func groupsToUpdateToDelete(newGroups, existingGroups []*ofp.OfpGroupEntry) (updatedNewGroups, groupsToDelete, updatedAllGroups []*ofp.OfpGroupEntry) {
+	existingByID := make(map[uint32]*ofp.OfpGroupEntry, len(existingGroups))
+	for _, g := range existingGroups {
+		existingByID[g.Desc.GroupId] = g
+	}
+
+	for _, newGroup := range newGroups {
+		existing, found := existingByID[newGroup.Desc.GroupId]
+		switch {
+		case !found:
+			updatedNewGroups = append(updatedNewGroups, newGroup)
+			updatedAllGroups = append(updatedAllGroups, newGroup)
+		case !proto.Equal(existing, newGroup):
+			updatedNewGroups = append(updatedNewGroups, newGroup)
+			groupsToDelete = append(groupsToDelete, existing)
+			updatedAllGroups = append(updatedAllGroups, newGroup)
+		default:
+			updatedAllGroups = append(updatedAllGroups, existing)
+		}
+		delete(existingByID, newGroup.Desc.GroupId)
+	}
+
+	// existingByID's range order is randomized per run, so sort the merged set by GroupId to make
+	// the result deterministic, same as flowsToUpdateToDelete.
+	remaining := make([]*ofp.OfpGroupEntry, 0, len(existingByID))
+	for _, g := range existingByID {
+		remaining = append(remaining, g)
+	}
+	updatedAllGroups = append(updatedAllGroups, remaining...)
+	sort.Slice(updatedAllGroups, func(i, j int) bool {
+		return updatedAllGroups[i].Desc.GroupId < updatedAllGroups[j].Desc.GroupId
+	})
+
+	return
+}
@@ -0,0 +1,93 @@
+/*
+* Copyright 2020-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package device
+
+import "fmt"
+
+// BatchUpdateMode selects how ExecuteFlowTableBatch reacts once an entry in the batch fails.
+type BatchUpdateMode int
+
+const (
+	// BatchBestEffort attempts every entry regardless of earlier failures; the result vector
+	// reports each entry's own outcome.
+	BatchBestEffort BatchUpdateMode = iota
+	// BatchAtomic stops at the first failed entry and undoes every entry already applied in this
+	// batch, in reverse order, so the logical device's flow and group tables end up unchanged.
+	BatchAtomic
+)
+
+// BatchEntryResult is UpdateLogicalDeviceFlowTableBatch's per-entry outcome, in the same order the
+// entries were submitted in.
+type BatchEntryResult struct {
+	Index      int
+	Success    bool
+	Error      string
+	RolledBack bool
+}
+
+// BatchMutation is one flow or group mod to apply as part of a batch. LogicalManager translates
+// each FlowMod/GroupMod in a FlowTableBatchUpdate into a BatchMutation bound to its own locked
+// flow/group table update APIs before calling ExecuteFlowTableBatch, so this package doesn't need
+// to know the difference between the two mod types.
+type BatchMutation struct {
+	// Apply performs the mod against the logical device. A non-nil error marks this entry failed
+	// and, under BatchAtomic, aborts the rest of the batch.
+	Apply func() error
+	// Undo reverses a previously applied Apply. Only invoked under BatchAtomic after a later entry
+	// in the same batch fails; a BatchMutation meant to run under BatchAtomic must set it.
+	Undo func() error
+}
+
+// ExecuteFlowTableBatch applies entries in order under mode and returns one BatchEntryResult per
+// entry, in submission order. Under BatchAtomic, an entry left unattempted because an earlier one
+// already failed is reported as its zero value (Success false, RolledBack false, Error empty).
+func ExecuteFlowTableBatch(entries []BatchMutation, mode BatchUpdateMode) []BatchEntryResult {
+	results := make([]BatchEntryResult, len(entries))
+	var applied []int
+
+	for i, entry := range entries {
+		results[i].Index = i
+		if err := entry.Apply(); err != nil {
+			results[i].Error = err.Error()
+			if mode == BatchAtomic {
+				rollbackApplied(entries, applied, results)
+				return results
+			}
+			continue
+		}
+		results[i].Success = true
+		applied = append(applied, i)
+	}
+	return results
+}
+
+// rollbackApplied undoes every index in applied, most recently applied first, updating results to
+// reflect the rollback.
+func rollbackApplied(entries []BatchMutation, applied []int, results []BatchEntryResult) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		idx := applied[i]
+		if entries[idx].Undo == nil {
+			continue
+		}
+		if err := entries[idx].Undo(); err != nil {
+			results[idx].Success = false
+			results[idx].Error = fmt.Sprintf("rollback-failed: %s", err)
+			continue
+		}
+		results[idx].Success = false
+		results[idx].RolledBack = true
+	}
+}
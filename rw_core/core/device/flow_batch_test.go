@@ -0,0 +1,117 @@
+/*
+* Copyright 2020-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package device
+
+import (
+	"fmt"
+	"testing"
+)
+
+// trackedMutation builds a BatchMutation that records its own apply/undo calls into log, failing
+// Apply when shouldFail is true.
+func trackedMutation(log *[]string, name string, shouldFail bool) BatchMutation {
+	return BatchMutation{
+		Apply: func() error {
+			if shouldFail {
+				return fmt.Errorf("%s-failed", name)
+			}
+			*log = append(*log, "apply-"+name)
+			return nil
+		},
+		Undo: func() error {
+			*log = append(*log, "undo-"+name)
+			return nil
+		},
+	}
+}
+
+func TestExecuteFlowTableBatchBestEffortKeepsGoingAfterFailure(t *testing.T) {
+	var log []string
+	entries := []BatchMutation{
+		trackedMutation(&log, "a", false),
+		trackedMutation(&log, "b", true),
+		trackedMutation(&log, "c", false),
+	}
+
+	results := ExecuteFlowTableBatch(entries, BatchBestEffort)
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if !results[0].Success || !results[2].Success {
+		t.Fatalf("expected entries a and c to succeed, got %+v", results)
+	}
+	if results[1].Success || results[1].Error == "" {
+		t.Fatalf("expected entry b to fail with an error, got %+v", results[1])
+	}
+	if results[0].RolledBack || results[2].RolledBack {
+		t.Fatalf("best-effort mode must never roll back, got %+v", results)
+	}
+	want := "apply-a apply-c"
+	got := fmt.Sprint(log)
+	if got != fmt.Sprint([]string{"apply-a", "apply-c"}) {
+		t.Fatalf("expected log %q, got %q", want, got)
+	}
+}
+
+func TestExecuteFlowTableBatchAtomicRollsBackOnFailure(t *testing.T) {
+	var log []string
+	entries := []BatchMutation{
+		trackedMutation(&log, "a", false),
+		trackedMutation(&log, "b", false),
+		trackedMutation(&log, "c", true),
+		trackedMutation(&log, "d", false),
+	}
+
+	results := ExecuteFlowTableBatch(entries, BatchAtomic)
+
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	if results[0].Success || !results[0].RolledBack {
+		t.Fatalf("expected entry a to be rolled back, got %+v", results[0])
+	}
+	if results[1].Success || !results[1].RolledBack {
+		t.Fatalf("expected entry b to be rolled back, got %+v", results[1])
+	}
+	if results[2].Success || results[2].Error == "" {
+		t.Fatalf("expected entry c to report its own failure, got %+v", results[2])
+	}
+	if results[3].Success || results[3].RolledBack {
+		t.Fatalf("expected entry d to never have been attempted, got %+v", results[3])
+	}
+
+	wantLog := []string{"apply-a", "apply-b", "undo-b", "undo-a"}
+	if fmt.Sprint(log) != fmt.Sprint(wantLog) {
+		t.Fatalf("expected rollback order %v, got %v", wantLog, log)
+	}
+}
+
+func TestExecuteFlowTableBatchAtomicAllSucceed(t *testing.T) {
+	var log []string
+	entries := []BatchMutation{
+		trackedMutation(&log, "a", false),
+		trackedMutation(&log, "b", false),
+	}
+
+	results := ExecuteFlowTableBatch(entries, BatchAtomic)
+
+	for i, res := range results {
+		if !res.Success || res.RolledBack {
+			t.Fatalf("entry %d: expected a clean success, got %+v", i, res)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+/*
+* Copyright 2019-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package device
+
+import (
+	"reflect"
+	"testing"
+
+	ofp "github.com/opencord/voltha-protos/v3/go/openflow_13"
+)
+
+func idsOf(flows []*ofp.OfpFlowStats) []uint64 {
+	ids := make([]uint64, len(flows))
+	for i, f := range flows {
+		ids[i] = f.Id
+	}
+	return ids
+}
+
+func TestFlowsToUpdateToDeleteAddUpdateUnchanged(t *testing.T) {
+	existing := []*ofp.OfpFlowStats{
+		{Id: 1, TableId: 0, Priority: 10, Cookie: 100}, // unchanged
+		{Id: 2, TableId: 0, Priority: 10, Cookie: 200}, // changed (cookie bumps, same Id)
+	}
+	newFlows := []*ofp.OfpFlowStats{
+		{Id: 1, TableId: 0, Priority: 10, Cookie: 100},
+		{Id: 2, TableId: 0, Priority: 10, Cookie: 201},
+		{Id: 3, TableId: 0, Priority: 10, Cookie: 300}, // new
+	}
+
+	updatedNew, toDelete, all := flowsToUpdateToDelete(newFlows, existing)
+
+	if got := idsOf(updatedNew); !reflect.DeepEqual(got, []uint64{2, 3}) {
+		t.Fatalf("updatedNewFlows ids = %v, want [2 3]", got)
+	}
+	if got := idsOf(toDelete); !reflect.DeepEqual(got, []uint64{2}) {
+		t.Fatalf("flowsToDelete ids = %v, want [2]", got)
+	}
+	if got := idsOf(all); !reflect.DeepEqual(got, []uint64{1, 2, 3}) {
+		t.Fatalf("updatedAllFlows ids = %v, want [1 2 3]", got)
+	}
+}
+
+func TestFlowsToUpdateToDeleteIsDeterministic(t *testing.T) {
+	existing := buildFlows(50, 0)
+	newFlows := []*ofp.OfpFlowStats{} // every existing flow becomes a "remaining" leftover
+
+	_, _, first := flowsToUpdateToDelete(newFlows, existing)
+	_, _, second := flowsToUpdateToDelete(newFlows, existing)
+
+	if !reflect.DeepEqual(idsOf(first), idsOf(second)) {
+		t.Fatalf("two runs over the same input produced different orders: %v vs %v", idsOf(first), idsOf(second))
+	}
+	for i := 1; i < len(first); i++ {
+		if first[i-1].Id > first[i].Id {
+			t.Fatalf("updatedAllFlows not sorted by Id: %v", idsOf(first))
+		}
+	}
+}
+
+func TestFlowsToUpdateToDeleteMatchesByHashAcrossIdChange(t *testing.T) {
+	// The adapter re-read the same flow (table/priority/cookie unchanged) but handed back a
+	// different Id - flowHashKey should still recognize it as the same flow and reconcile it as
+	// one delete-old+add-new pair, rather than treating the old Id as an untouched leftover
+	// alongside an unrelated add (which would leave both Id 1 and Id 99 in updatedAllFlows).
+	existing := []*ofp.OfpFlowStats{
+		{Id: 1, TableId: 0, Priority: 10, Cookie: 42},
+	}
+	newFlows := []*ofp.OfpFlowStats{
+		{Id: 99, TableId: 0, Priority: 10, Cookie: 42},
+	}
+
+	updatedNew, toDelete, all := flowsToUpdateToDelete(newFlows, existing)
+
+	if got := idsOf(updatedNew); !reflect.DeepEqual(got, []uint64{99}) {
+		t.Fatalf("updatedNewFlows ids = %v, want [99]", got)
+	}
+	if got := idsOf(toDelete); !reflect.DeepEqual(got, []uint64{1}) {
+		t.Fatalf("flowsToDelete ids = %v, want [1] (the stale pre-hash-rename entry)", got)
+	}
+	if got := idsOf(all); !reflect.DeepEqual(got, []uint64{99}) {
+		t.Fatalf("updatedAllFlows ids = %v, want [99] only - Id 1 must not also linger as a leftover", got)
+	}
+}
+
+func TestFlowsToUpdateToDeleteHashMatchOverIdCollision(t *testing.T) {
+	// newFlow's hash matches existing flow B, but its Id collides with an unrelated existing flow
+	// A. A must not leak through untouched sharing newFlow's Id in updatedAllFlows - it has to be
+	// reconciled (deleted) instead.
+	a := &ofp.OfpFlowStats{Id: 5, TableId: 0, Priority: 10, Cookie: 1}
+	b := &ofp.OfpFlowStats{Id: 7, TableId: 0, Priority: 10, Cookie: 2}
+	existing := []*ofp.OfpFlowStats{a, b}
+	newFlow := &ofp.OfpFlowStats{Id: 5, TableId: 0, Priority: 10, Cookie: 2} // same hash as b, Id of a
+
+	_, toDelete, all := flowsToUpdateToDelete([]*ofp.OfpFlowStats{newFlow}, existing)
+
+	deletedIds := map[uint64]bool{}
+	for _, f := range toDelete {
+		deletedIds[f.Id] = true
+	}
+	if !deletedIds[7] {
+		t.Fatalf("expected b (Id 7, matched by hash) to be deleted, flowsToDelete = %v", idsOf(toDelete))
+	}
+	if !deletedIds[5] {
+		t.Fatalf("expected a (Id 5, the colliding unrelated entry) to be deleted, flowsToDelete = %v", idsOf(toDelete))
+	}
+
+	idCounts := map[uint64]int{}
+	for _, f := range all {
+		idCounts[f.Id]++
+	}
+	for id, count := range idCounts {
+		if count > 1 {
+			t.Fatalf("updatedAllFlows has %d entries with Id %d, want at most 1: %v", count, id, idsOf(all))
+		}
+	}
+}
+
+func buildFlows(n int, cookieOffset uint64) []*ofp.OfpFlowStats {
+	flows := make([]*ofp.OfpFlowStats, 0, n)
+	for i := 0; i < n; i++ {
+		flows = append(flows, &ofp.OfpFlowStats{
+			Id:      uint64(i),
+			TableId: uint32(i % 8),
+			Cookie:  uint64(i) + cookieOffset,
+		})
+	}
+	return flows
+}
+
+func BenchmarkFlowsToUpdateToDelete10k(b *testing.B) {
+	existingFlows := buildFlows(10000, 0)
+	newFlows := buildFlows(10000, 1) // every cookie changed, forcing delete+add for all
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		flowsToUpdateToDelete(newFlows, existingFlows)
+	}
+}
+
+func BenchmarkGroupsToUpdateToDelete10k(b *testing.B) {
+	existingGroups := make([]*ofp.OfpGroupEntry, 0, 10000)
+	newGroups := make([]*ofp.OfpGroupEntry, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		existingGroups = append(existingGroups, &ofp.OfpGroupEntry{Desc: &ofp.OfpGroupDesc{GroupId: uint32(i)}})
+		newGroups = append(newGroups, &ofp.OfpGroupEntry{Desc: &ofp.OfpGroupDesc{GroupId: uint32(i), Type: 1}})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		groupsToUpdateToDelete(newGroups, existingGroups)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package device
+
+import (
+	"sync"
+
+	"github.com/opencord/voltha-protos/v3/go/voltha"
+)
+
+// ImageDownloadProgressBroker fans out ImageDownload progress updates - percentage, bytes
+// transferred, and state transitions, exactly as the adapter reports them - to every
+// StreamImageDownloadStatus caller watching a given (deviceId, imageName) pair. Manager.
+// DownloadImage publishes to it as the adapter's updates arrive; NBIHandler.
+// StreamImageDownloadStatus subscribes and relays what it receives straight to its gRPC stream.
+type ImageDownloadProgressBroker struct {
+	mu          sync.Mutex
+	subscribers map[string][]chan *voltha.ImageDownload
+}
+
+// NewImageDownloadProgressBroker returns an empty ImageDownloadProgressBroker.
+func NewImageDownloadProgressBroker() *ImageDownloadProgressBroker {
+	return &ImageDownloadProgressBroker{subscribers: make(map[string][]chan *voltha.ImageDownload)}
+}
+
+func progressKey(deviceID, imageName string) string {
+	return deviceID + "/" + imageName
+}
+
+// Subscribe registers a new listener for (deviceID, imageName) updates and returns the channel it
+// will receive them on along with an unsubscribe func the caller must invoke once it stops
+// reading, typically in a defer alongside closing its own forwarding loop.
+func (b *ImageDownloadProgressBroker) Subscribe(deviceID, imageName string) (<-chan *voltha.ImageDownload, func()) {
+	key := progressKey(deviceID, imageName)
+	ch := make(chan *voltha.ImageDownload, 10)
+
+	b.mu.Lock()
+	b.subscribers[key] = append(b.subscribers[key], ch)
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subscribers[key]
+		for i, sub := range subs {
+			if sub == ch {
+				b.subscribers[key] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}
+	return ch, unsubscribe
+}
+
+// Publish delivers update to every current subscriber of its (DeviceId, Name) pair. A subscriber
+// that isn't keeping up with its buffered channel is skipped rather than blocking the publisher,
+// since a slow NBI client should not stall the download itself.
+func (b *ImageDownloadProgressBroker) Publish(update *voltha.ImageDownload) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subscribers[progressKey(update.DeviceId, update.Name)] {
+		select {
+		case ch <- update:
+		default:
+		}
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2020-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package device
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/db/kvstore"
+	"github.com/opencord/voltha-protos/v3/go/voltha"
+)
+
+// imageDownloadStateKVPrefix namespaces persisted download progress in the core's existing KV
+// store, the same one device state is kept in, rather than introducing a second storage system.
+const imageDownloadStateKVPrefix = "service/voltha/image_download_state"
+
+// ImageDownloadState is the resumable progress of one (deviceId, imageName) transfer: how many
+// bytes of it the adapter has acknowledged and the running checksum computed over them.
+// Manager.DownloadImage saves it after every acknowledged chunk and loads it before starting a
+// transfer, so a DownloadImage call issued after a core restart or adapter crash resumes from the
+// last acknowledged chunk instead of restarting the whole image.
+type ImageDownloadState struct {
+	DeviceID  string
+	ImageName string
+	Offset    int64
+	Checksum  string
+	State     voltha.ImageDownload_ImageDownloadState
+}
+
+// ImageDownloadStateStore persists ImageDownloadState across core restarts the same way
+// model.RevisionStore pages branch revisions: keyed records in a kvstore.Client rather than kept
+// resident only in memory.
+type ImageDownloadStateStore struct {
+	client kvstore.Client
+}
+
+// NewImageDownloadStateStore returns an ImageDownloadStateStore backed by client.
+func NewImageDownloadStateStore(client kvstore.Client) *ImageDownloadStateStore {
+	return &ImageDownloadStateStore{client: client}
+}
+
+func imageDownloadStateKey(deviceID, imageName string) string {
+	return fmt.Sprintf("%s/%s/%s", imageDownloadStateKVPrefix, deviceID, imageName)
+}
+
+// Save persists state, overwriting whatever was previously saved for the same device/image pair.
+func (s *ImageDownloadStateStore) Save(state *ImageDownloadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("image-download-state-marshal-%s-%s: %w", state.DeviceID, state.ImageName, err)
+	}
+	return s.client.Put(imageDownloadStateKey(state.DeviceID, state.ImageName), data)
+}
+
+// Load retrieves the last state saved for (deviceID, imageName). found is false, with a nil
+// error, if no download has ever been started for that pair - DownloadImage should start from
+// offset zero in that case rather than treating it as an error.
+func (s *ImageDownloadStateStore) Load(deviceID, imageName string) (state *ImageDownloadState, found bool, err error) {
+	pair, err := s.client.Get(imageDownloadStateKey(deviceID, imageName))
+	if err != nil {
+		return nil, false, fmt.Errorf("image-download-state-get-%s-%s: %w", deviceID, imageName, err)
+	}
+	if pair == nil {
+		return nil, false, nil
+	}
+	data, ok := pair.Value.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("image-download-state-get-%s-%s: unexpected value type %T", deviceID, imageName, pair.Value)
+	}
+	state = &ImageDownloadState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, false, fmt.Errorf("image-download-state-unmarshal-%s-%s: %w", deviceID, imageName, err)
+	}
+	return state, true, nil
+}
+
+// Delete removes the saved state for (deviceID, imageName), typically once the transfer has
+// completed or been cancelled; deleting a pair that was never saved is not an error.
+func (s *ImageDownloadStateStore) Delete(deviceID, imageName string) error {
+	return s.client.Delete(imageDownloadStateKey(deviceID, imageName))
+}
@@ -0,0 +1,52 @@
+/*
+* Copyright 2019-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package device
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	var m dto.Metric
+	assert.Nil(t, c.Write(&m))
+	return m.GetCounter().GetValue()
+}
+
+func TestMetricsRecordFlowReconciliation(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+
+	metrics.RecordFlowReconciliation(2, 1, 3)
+
+	assert.Equal(t, float64(2), counterValue(t, metrics.FlowsAdded))
+	assert.Equal(t, float64(1), counterValue(t, metrics.FlowsDeleted))
+	assert.Equal(t, float64(3), counterValue(t, metrics.FlowsUnchanged))
+}
+
+func TestMetricsObserveMethodLatency(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+
+	done := metrics.ObserveMethodLatency("addPort")
+	done()
+
+	var m dto.Metric
+	assert.Nil(t, metrics.MethodLatency.WithLabelValues("addPort").(prometheus.Histogram).Write(&m))
+	assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+}
@@ -0,0 +1,79 @@
+/*
+* Copyright 2019-present Open Networking Foundation
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+* http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package device
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer is the OpenTelemetry tracer used by every Agent method that starts a span. It is
+// configured once at startup from the OTLP exporter endpoint in config.RWCoreFlags.
+var tracer = otel.Tracer("voltha-go/rw_core/core/device")
+
+// StartAgentSpan starts a span for an Agent method, tagging it with the device/parent id so a
+// trace backend can stitch together the concurrent update paths exercised by
+// TestConcurrentDevices. Callers should `defer span.End()`. Exported so rw_core/core's
+// DeviceAgent, which lives in a different package, can start spans around its own methods.
+func StartAgentSpan(ctx context.Context, operation, deviceID, parentID string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, operation, trace.WithAttributes(
+		attribute.String("device.id", deviceID),
+		attribute.String("parent.id", parentID),
+	))
+}
+
+// StartAgentSpanWithAdapter is the same as StartAgentSpan but also tags the owning adapter, for
+// methods whose latency is dominated by adapter round trips.
+func StartAgentSpanWithAdapter(ctx context.Context, operation, deviceID, parentID, adapterName string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, operation, trace.WithAttributes(
+		attribute.String("device.id", deviceID),
+		attribute.String("parent.id", parentID),
+		attribute.String("adapter", adapterName),
+	))
+}
+
+// RecordFlowGroupCounts adds the OpFlow/Group reconciliation counts produced by
+// flowsToUpdateToDelete/groupsToUpdateToDelete as span attributes, so a slow reconciliation can
+// be correlated with how many entries it touched.
+func RecordFlowGroupCounts(span trace.Span, toAdd, toDelete, unchanged int) {
+	span.SetAttributes(
+		attribute.Int("flows.to_add", toAdd),
+		attribute.Int("flows.to_delete", toDelete),
+		attribute.Int("flows.unchanged", unchanged),
+	)
+}
+
+// InjectTraceContext serializes the span context carried by ctx into a Kafka message's headers so
+// an inter-container proxy call propagates the trace across the container boundary. Exported so a
+// future kmp.InterContainerProxy - this tree has no such proxy checked in yet to call it from -
+// can use it once it exists.
+func InjectTraceContext(ctx context.Context, headers map[string]string) map[string]string {
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+	return headers
+}
+
+// ExtractTraceContext rebuilds a context carrying the span context found in a Kafka message's
+// headers, so the receiving core continues the caller's trace instead of starting a new one.
+func ExtractTraceContext(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
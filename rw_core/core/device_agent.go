@@ -17,7 +17,6 @@ package core
 
 import (
 	"context"
-	"reflect"
 	"sync"
 
 	"github.com/gogo/protobuf/proto"
@@ -25,19 +24,39 @@ import (
 	"github.com/opencord/voltha-go/db/model"
 	"github.com/opencord/voltha-go/protos/core_adapter"
 	"github.com/opencord/voltha-go/protos/voltha"
+	"github.com/opencord/voltha-go/rw_core/core/device"
+	"github.com/opencord/voltha-go/rw_core/core/fsm"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// TransitionEvent is emitted on transitionEvents whenever the device FSM completes a transition,
+// so operators can subscribe to state changes instead of polling the device.
+type TransitionEvent struct {
+	DeviceID string
+	Event    fsm.Event
+	From     fsm.State
+	To       fsm.State
+}
+
 type DeviceAgent struct {
 	deviceId         string
 	lastData         *voltha.Device
 	adapterProxy     *AdapterProxy
 	deviceMgr        *DeviceManager
 	clusterDataProxy *model.Proxy
-	deviceProxy      *model.Proxy
-	exitChannel      chan int
-	lockDevice       sync.RWMutex
+	// postUpdateCallbacks replaces the legacy deviceProxy.RegisterCallback(model.POST_UPDATE, ...)
+	// wiring: there is no real Proxy/Root pub-sub invoking callbacks on our behalf, so this agent
+	// registers against its own typed registry and invokes it itself at every point it commits a
+	// device update, instead of asserting args ...interface{} apart by hand.
+	postUpdateCallbacks *model.CallbackRegistry[*voltha.Device]
+	exitChannel         chan int
+	lockDevice          sync.RWMutex // guards device-level fields: admin/oper/connect state
+	lockPorts           sync.RWMutex // guards the device's ports list
+	lockPmConfigs       sync.RWMutex // guards the device's PM configuration
+	fsm                 *fsm.DeviceFSM
+	transitionEvents    chan TransitionEvent
+	ops                 *opRegistry // dedupes/cancels enable/disable/reboot/delete, keyed by fsm.Event
 }
 
 //newDeviceAgent creates a new device agent along as creating a unique ID for the device and set the device state to
@@ -54,11 +73,159 @@ func newDeviceAgent(ap *AdapterProxy, device *voltha.Device, deviceMgr *DeviceMa
 	agent.exitChannel = make(chan int, 1)
 	agent.clusterDataProxy = cdProxy
 	agent.lockDevice = sync.RWMutex{}
+	agent.lockPorts = sync.RWMutex{}
+	agent.lockPmConfigs = sync.RWMutex{}
+	agent.transitionEvents = make(chan TransitionEvent, 10)
+	agent.ops = newOpRegistry()
+	agent.postUpdateCallbacks = model.NewCallbackRegistry[*voltha.Device]("POST_UPDATE")
+	agent.fsm = agent.buildFSM()
 	return &agent
 }
 
+// buildFSM assembles the allowed admin-state transitions for a device: PREPROVISIONED/DISABLED
+// -(enable)-> ENABLED, ENABLED -(disable)-> DISABLED, DISABLED -(reboot)-> DISABLED, and
+// DISABLED -(delete)-> DELETED. Each action performs the adapter RPC and returns the resulting
+// State; dispatchDeviceEvent then drives the model update and transitionEvents notification
+// common to every transition.
+func (agent *DeviceAgent) buildFSM() *fsm.DeviceFSM {
+	f := fsm.NewDeviceFSM()
+
+	f.AddTransition(voltha.AdminState_PREPROVISIONED, fsm.EventEnable, nil,
+		func(ctx context.Context, current fsm.State) (fsm.State, error) {
+			device, err := agent.getDeviceWithoutLock()
+			if err != nil {
+				return current, err
+			}
+			if err := agent.adapterProxy.AdoptDevice(ctx, device); err != nil {
+				return current, err
+			}
+			return fsm.State{Admin: voltha.AdminState_ENABLED, Oper: voltha.OperStatus_ACTIVATING, Connect: current.Connect}, nil
+		})
+
+	f.AddTransition(voltha.AdminState_DISABLED, fsm.EventEnable, nil,
+		func(ctx context.Context, current fsm.State) (fsm.State, error) {
+			device, err := agent.getDeviceWithoutLock()
+			if err != nil {
+				return current, err
+			}
+			if err := agent.adapterProxy.ReEnableDevice(ctx, device); err != nil {
+				return current, err
+			}
+			return fsm.State{Admin: voltha.AdminState_ENABLED, Oper: voltha.OperStatus_ACTIVATING, Connect: current.Connect}, nil
+		})
+
+	f.AddTransition(voltha.AdminState_ENABLED, fsm.EventDisable, nil,
+		func(ctx context.Context, current fsm.State) (fsm.State, error) {
+			device, err := agent.getDeviceWithoutLock()
+			if err != nil {
+				return current, err
+			}
+			if err := agent.adapterProxy.DisableDevice(ctx, device); err != nil {
+				return current, err
+			}
+			return fsm.State{Admin: voltha.AdminState_DISABLED, Oper: current.Oper, Connect: current.Connect}, nil
+		})
+
+	f.AddTransition(voltha.AdminState_DISABLED, fsm.EventReboot, nil,
+		func(ctx context.Context, current fsm.State) (fsm.State, error) {
+			device, err := agent.getDeviceWithoutLock()
+			if err != nil {
+				return current, err
+			}
+			if err := agent.adapterProxy.RebootDevice(ctx, device); err != nil {
+				return current, err
+			}
+			return current, nil
+		})
+
+	f.AddTransition(voltha.AdminState_DISABLED, fsm.EventDelete, nil,
+		func(ctx context.Context, current fsm.State) (fsm.State, error) {
+			device, err := agent.getDeviceWithoutLock()
+			if err != nil {
+				return current, err
+			}
+			if err := agent.adapterProxy.DeleteDevice(ctx, device); err != nil {
+				return current, err
+			}
+			return fsm.State{Admin: voltha.AdminState_DELETED, Oper: current.Oper, Connect: current.Connect}, nil
+		})
+
+	return f
+}
+
+// currentFSMState reads the device's admin/oper/connect triple without re-acquiring lockDevice;
+// callers must already hold it.
+func (agent *DeviceAgent) currentFSMState() (fsm.State, *voltha.Device, error) {
+	device, err := agent.getDeviceWithoutLock()
+	if err != nil {
+		return fsm.State{}, nil, err
+	}
+	return fsm.State{Admin: device.AdminState, Oper: device.OperStatus, Connect: device.ConnectStatus}, device, nil
+}
+
+// dispatchDeviceEvent drives one FSM transition end to end: it loads the current state, asks the
+// FSM to run the matching guard/action (the adapter RPC), writes the resulting state back to the
+// model, notifies transitionEvents, and triggers deviceMgr.processTransition the same way every
+// admin-state change used to before the FSM existed. The whole sequence runs under
+// runDedupedOp, keyed by event, so a retried NBI call or a duplicate dispatch from a failed-over
+// leader joins the original adapter RPC instead of issuing it twice.
+func (agent *DeviceAgent) dispatchDeviceEvent(ctx context.Context, event fsm.Event) error {
+	return agent.runDedupedOp(ctx, event, func(ctx context.Context) error {
+		current, dev, err := agent.currentFSMState()
+		if err != nil {
+			return err
+		}
+
+		ctx, span := device.StartAgentSpan(ctx, "dispatchDeviceEvent", agent.deviceId, dev.ParentId)
+		defer span.End()
+
+		next, err := agent.fsm.Dispatch(ctx, current, event)
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			// The caller gave up waiting while the adapter RPC above was still in flight; the FSM
+			// action already happened against the adapter, but skip committing the model update so
+			// a subsequent replay with the same opID still sees the pre-transition state and retries.
+			return ctx.Err()
+		}
+
+		cloned := proto.Clone(dev).(*voltha.Device)
+		cloned.AdminState = next.Admin
+		cloned.OperStatus = next.Oper
+		cloned.ConnectStatus = next.Connect
+		if afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, ""); afterUpdate == nil {
+			return status.Errorf(codes.Internal, "failed-update-device:%s", agent.deviceId)
+		}
+		if err := agent.postUpdateCallbacks.Invoke(ctx, "/devices/"+agent.deviceId, cloned); err != nil {
+			log.Warnw("post-update-callback-failed", log.Fields{"deviceId": agent.deviceId, "error": err})
+		}
+
+		agent.notifyTransition(event, current, next)
+
+		if err := agent.deviceMgr.processTransition(dev, cloned); err != nil {
+			log.Warnw("process-transition-error", log.Fields{"deviceid": dev.Id, "error": err})
+			return err
+		}
+		return nil
+	})
+}
+
+// notifyTransition publishes a TransitionEvent on transitionEvents without blocking; a slow or
+// absent subscriber just means the event is dropped rather than stalling the device agent.
+func (agent *DeviceAgent) notifyTransition(event fsm.Event, from, to fsm.State) {
+	select {
+	case agent.transitionEvents <- TransitionEvent{DeviceID: agent.deviceId, Event: event, From: from, To: to}:
+	default:
+		log.Warnw("transition-event-dropped", log.Fields{"deviceId": agent.deviceId, "event": event})
+	}
+}
+
 // start save the device to the data model and registers for callbacks on that device
 func (agent *DeviceAgent) start(ctx context.Context) {
+	_, span := device.StartAgentSpan(ctx, "start", agent.deviceId, agent.lastData.ParentId)
+	defer span.End()
+
 	agent.lockDevice.Lock()
 	defer agent.lockDevice.Unlock()
 	log.Debugw("starting-device-agent", log.Fields{"device": agent.lastData})
@@ -66,8 +233,7 @@ func (agent *DeviceAgent) start(ctx context.Context) {
 	if added := agent.clusterDataProxy.Add("/devices", agent.lastData, ""); added == nil {
 		log.Errorw("failed-to-add-device", log.Fields{"deviceId": agent.deviceId})
 	}
-	agent.deviceProxy = agent.clusterDataProxy.Root.GetProxy("/devices/"+agent.deviceId, false)
-	agent.deviceProxy.RegisterCallback(model.POST_UPDATE, agent.processUpdate, nil)
+	agent.postUpdateCallbacks.Register(agent.processUpdate)
 	log.Debug("device-agent-started")
 }
 
@@ -105,150 +271,117 @@ func (agent *DeviceAgent) getDeviceWithoutLock() (*voltha.Device, error) {
 	return nil, status.Errorf(codes.NotFound, "device-%s", agent.deviceId)
 }
 
-// enableDevice activates a preprovisioned or disable device
+// maxUpdateRetries bounds how many times updateDeviceWithRetry re-fetches and retries a mutation
+// after a conflicting write, rather than retrying forever.
+const maxUpdateRetries = 3
+
+// updateDeviceWithRetry re-fetches the latest device, applies mutate to a clone of it, and commits
+// the clone via clusterDataProxy.Update. clusterDataProxy.Update returns nil when the stored
+// revision moved out from under us (a concurrent writer committed first); on that conflict this
+// re-fetches the now-latest device and retries mutate, up to maxUpdateRetries times, instead of
+// blindly overwriting the concurrent update. Callers must hold whichever lock guards the fields
+// mutate touches.
+func (agent *DeviceAgent) updateDeviceWithRetry(mutate func(cloned *voltha.Device) error) (before, after *voltha.Device, err error) {
+	for attempt := 0; attempt < maxUpdateRetries; attempt++ {
+		before, err = agent.getDeviceWithoutLock()
+		if err != nil {
+			return nil, nil, err
+		}
+		cloned := proto.Clone(before).(*voltha.Device)
+		if err := mutate(cloned); err != nil {
+			return nil, nil, err
+		}
+		if afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, ""); afterUpdate != nil {
+			if cbErr := agent.postUpdateCallbacks.Invoke(context.Background(), "/devices/"+agent.deviceId, cloned); cbErr != nil {
+				log.Warnw("post-update-callback-failed", log.Fields{"deviceId": agent.deviceId, "error": cbErr})
+			}
+			return before, cloned, nil
+		}
+		log.Debugw("update-conflict-retrying", log.Fields{"deviceId": agent.deviceId, "attempt": attempt})
+	}
+	return nil, nil, status.Errorf(codes.Internal, "update-conflict-exhausted-retries:%s", agent.deviceId)
+}
+
+// enableDevice activates a preprovisioned or disabled device by dispatching fsm.EventEnable;
+// the FSM itself decides whether that means adopting the device for the first time or
+// re-enabling a previously disabled one.
 func (agent *DeviceAgent) enableDevice(ctx context.Context) error {
 	agent.lockDevice.Lock()
 	defer agent.lockDevice.Unlock()
 	log.Debugw("enableDevice", log.Fields{"id": agent.deviceId})
-	if device, err := agent.getDeviceWithoutLock(); err != nil {
+
+	device, err := agent.getDeviceWithoutLock()
+	if err != nil {
 		return status.Errorf(codes.NotFound, "%s", agent.deviceId)
-	} else {
-		if device.AdminState == voltha.AdminState_ENABLED {
-			log.Debugw("device-already-enabled", log.Fields{"id": agent.deviceId})
-			//TODO:  Needs customized error message
-			return nil
-		}
-		//TODO: if parent device is disabled then do not enable device
-		// Verify whether we need to adopt the device the first time
-		// TODO: A state machine for these state transitions would be better (we just have to handle
-		// a limited set of states now or it may be an overkill)
-		if device.AdminState == voltha.AdminState_PREPROVISIONED {
-			// First send the request to an Adapter and wait for a response
-			if err := agent.adapterProxy.AdoptDevice(ctx, device); err != nil {
-				log.Debugw("adoptDevice-error", log.Fields{"id": agent.lastData.Id, "error": err})
-				return err
-			}
-		} else {
-			// First send the request to an Adapter and wait for a response
-			if err := agent.adapterProxy.ReEnableDevice(ctx, device); err != nil {
-				log.Debugw("renableDevice-error", log.Fields{"id": agent.lastData.Id, "error": err})
-				return err
-			}
-		}
-		// Received an Ack (no error found above).  Now update the device in the model to the expected state
-		cloned := proto.Clone(device).(*voltha.Device)
-		cloned.AdminState = voltha.AdminState_ENABLED
-		cloned.OperStatus = voltha.OperStatus_ACTIVATING
-		if afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, ""); afterUpdate == nil {
-			return status.Errorf(codes.Internal, "failed-update-device:%s", agent.deviceId)
-		}
 	}
-	return nil
+	if device.AdminState == voltha.AdminState_ENABLED {
+		log.Debugw("device-already-enabled", log.Fields{"id": agent.deviceId})
+		return nil
+	}
+	//TODO: if parent device is disabled then do not enable device
+	return agent.dispatchDeviceEvent(ctx, fsm.EventEnable)
 }
 
-//disableDevice disable a device
+//disableDevice disable a device by dispatching fsm.EventDisable
 func (agent *DeviceAgent) disableDevice(ctx context.Context) error {
 	agent.lockDevice.Lock()
-	//defer agent.lockDevice.Unlock()
+	defer agent.lockDevice.Unlock()
 	log.Debugw("disableDevice", log.Fields{"id": agent.deviceId})
-	// Get the most up to date the device info
-	if device, err := agent.getDeviceWithoutLock(); err != nil {
+
+	device, err := agent.getDeviceWithoutLock()
+	if err != nil {
 		return status.Errorf(codes.NotFound, "%s", agent.deviceId)
-	} else {
-		if device.AdminState == voltha.AdminState_DISABLED {
-			log.Debugw("device-already-disabled", log.Fields{"id": agent.deviceId})
-			//TODO:  Needs customized error message
-			agent.lockDevice.Unlock()
-			return nil
-		}
-		// First send the request to an Adapter and wait for a response
-		if err := agent.adapterProxy.DisableDevice(ctx, device); err != nil {
-			log.Debugw("disableDevice-error", log.Fields{"id": agent.lastData.Id, "error": err})
-			agent.lockDevice.Unlock()
-			return err
-		}
-		// Received an Ack (no error found above).  Now update the device in the model to the expected state
-		cloned := proto.Clone(device).(*voltha.Device)
-		cloned.AdminState = voltha.AdminState_DISABLED
-		// Set the state of all ports on that device to disable
+	}
+	if device.AdminState == voltha.AdminState_DISABLED {
+		log.Debugw("device-already-disabled", log.Fields{"id": agent.deviceId})
+		return nil
+	}
+	if err := agent.dispatchDeviceEvent(ctx, fsm.EventDisable); err != nil {
+		return err
+	}
+	// Set the state of all ports on that device to disable. This touches the ports list rather
+	// than admin/oper/connect state, so it is guarded by lockPorts instead of lockDevice.
+	agent.lockPorts.Lock()
+	defer agent.lockPorts.Unlock()
+	_, _, err = agent.updateDeviceWithRetry(func(cloned *voltha.Device) error {
 		for _, port := range cloned.Ports {
 			port.AdminState = voltha.AdminState_DISABLED
 			port.OperStatus = voltha.OperStatus_UNKNOWN
 		}
-		if afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, ""); afterUpdate == nil {
-			agent.lockDevice.Unlock()
-			return status.Errorf(codes.Internal, "failed-update-device:%s", agent.deviceId)
-		}
-		agent.lockDevice.Unlock()
-		//TODO: callback will be invoked to handle this state change
-		//For now force the state transition to happen
-		if err := agent.deviceMgr.processTransition(device, cloned); err != nil {
-			log.Warnw("process-transition-error", log.Fields{"deviceid": device.Id, "error": err})
-			return err
-		}
-	}
-	return nil
+		return nil
+	})
+	return err
 }
 
+// rebootDevice reboots a disabled device by dispatching fsm.EventReboot; the FSM rejects the event
+// (via TransitionError) if the device is not currently DISABLED, since no reboot transition is
+// registered from any other admin state.
 func (agent *DeviceAgent) rebootDevice(ctx context.Context) error {
 	agent.lockDevice.Lock()
 	defer agent.lockDevice.Unlock()
 	log.Debugw("rebootDevice", log.Fields{"id": agent.deviceId})
-	// Get the most up to date the device info
-	if device, err := agent.getDeviceWithoutLock(); err != nil {
-		return status.Errorf(codes.NotFound, "%s", agent.deviceId)
-	} else {
-		if device.AdminState != voltha.AdminState_DISABLED {
-			log.Debugw("device-not-disabled", log.Fields{"id": agent.deviceId})
-			//TODO:  Needs customized error message
+
+	if err := agent.dispatchDeviceEvent(ctx, fsm.EventReboot); err != nil {
+		if _, ok := err.(*fsm.TransitionError); ok {
 			return status.Errorf(codes.FailedPrecondition, "deviceId:%s, expected-admin-state:%s", agent.deviceId, voltha.AdminState_DISABLED)
 		}
-		// First send the request to an Adapter and wait for a response
-		if err := agent.adapterProxy.RebootDevice(ctx, device); err != nil {
-			log.Debugw("rebootDevice-error", log.Fields{"id": agent.lastData.Id, "error": err})
-			return err
-		}
+		return err
 	}
 	return nil
 }
 
+// deleteDevice removes a disabled device by dispatching fsm.EventDelete, which sets the device's
+// AdminState to DELETED in order to trigger the callback that deletes any child devices.
 func (agent *DeviceAgent) deleteDevice(ctx context.Context) error {
 	agent.lockDevice.Lock()
+	defer agent.lockDevice.Unlock()
 	log.Debugw("deleteDevice", log.Fields{"id": agent.deviceId})
-	// Get the most up to date the device info
-	if device, err := agent.getDeviceWithoutLock(); err != nil {
-		agent.lockDevice.Unlock()
-		return status.Errorf(codes.NotFound, "%s", agent.deviceId)
-	} else {
-		if device.AdminState != voltha.AdminState_DISABLED {
-			log.Debugw("device-not-disabled", log.Fields{"id": agent.deviceId})
-			//TODO:  Needs customized error message
-			agent.lockDevice.Unlock()
+
+	if err := agent.dispatchDeviceEvent(ctx, fsm.EventDelete); err != nil {
+		if _, ok := err.(*fsm.TransitionError); ok {
 			return status.Errorf(codes.FailedPrecondition, "deviceId:%s, expected-admin-state:%s", agent.deviceId, voltha.AdminState_DISABLED)
 		}
-		// Send the request to an Adapter and wait for a response
-		if err := agent.adapterProxy.DeleteDevice(ctx, device); err != nil {
-			log.Debugw("deleteDevice-error", log.Fields{"id": agent.lastData.Id, "error": err})
-			agent.lockDevice.Unlock()
-			return err
-		}
-		//	Set the device Admin state to DELETED in order to trigger the callback to delete
-		// child devices, if any
-		// Received an Ack (no error found above).  Now update the device in the model to the expected state
-		cloned := proto.Clone(device).(*voltha.Device)
-		cloned.AdminState = voltha.AdminState_DELETED
-		if afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, ""); afterUpdate == nil {
-			agent.lockDevice.Unlock()
-			return status.Errorf(codes.Internal, "failed-update-device:%s", agent.deviceId)
-		}
-		agent.lockDevice.Unlock()
-		//TODO: callback will be invoked to handle this state change
-		//For now force the state transition to happen
-		if err := agent.deviceMgr.processTransition(device, cloned); err != nil {
-			log.Warnw("process-transition-error", log.Fields{"deviceid": device.Id, "error": err})
-			return err
-		}
-
+		return err
 	}
 	return nil
 }
@@ -301,11 +434,11 @@ func (agent *DeviceAgent) getPortCapability(ctx context.Context, portNo uint32)
 	}
 }
 
-// TODO: implement when callback from the data model is ready
-// processUpdate is a callback invoked whenever there is a change on the device manages by this device agent
-func (agent *DeviceAgent) processUpdate(args ...interface{}) interface{} {
-	log.Debug("!!!!!!!!!!!!!!!!!!!!!!!!!")
-	log.Debugw("processUpdate", log.Fields{"deviceId": agent.deviceId, "args": args})
+// processUpdate is registered against postUpdateCallbacks in start() and runs whenever this
+// agent commits a device update, migrated off the legacy args ...interface{} callback (which
+// asserted its device out of args by hand) onto the typed Callback[*voltha.Device] signature.
+func (agent *DeviceAgent) processUpdate(ctx context.Context, device *voltha.Device) error {
+	log.Debugw("processUpdate", log.Fields{"deviceId": agent.deviceId, "device": device})
 	return nil
 }
 
@@ -325,6 +458,9 @@ func (agent *DeviceAgent) updateDevice(device *voltha.Device) error {
 		if afterUpdate == nil {
 			return status.Errorf(codes.Internal, "%s", device.Id)
 		}
+		if cbErr := agent.postUpdateCallbacks.Invoke(context.Background(), "/devices/"+device.Id, cloned); cbErr != nil {
+			log.Warnw("post-update-callback-failed", log.Fields{"deviceId": device.Id, "error": cbErr})
+		}
 		// Perform the state transition
 		if err := agent.deviceMgr.processTransition(storedData, cloned); err != nil {
 			log.Warnw("process-transition-error", log.Fields{"deviceid": device.Id, "error": err})
@@ -335,15 +471,12 @@ func (agent *DeviceAgent) updateDevice(device *voltha.Device) error {
 }
 
 func (agent *DeviceAgent) updateDeviceStatus(operStatus voltha.OperStatus_OperStatus, connStatus voltha.ConnectStatus_ConnectStatus) error {
+	_, span := device.StartAgentSpan(context.Background(), "updateDeviceStatus", agent.deviceId, agent.lastData.ParentId)
+	defer span.End()
+
 	agent.lockDevice.Lock()
-	//defer agent.lockDevice.Unlock()
-	// Work only on latest data
-	if storeDevice, err := agent.getDeviceWithoutLock(); err != nil {
-		agent.lockDevice.Unlock()
-		return status.Errorf(codes.NotFound, "%s", agent.deviceId)
-	} else {
-		// clone the device
-		cloned := proto.Clone(storeDevice).(*voltha.Device)
+	defer agent.lockDevice.Unlock()
+	before, cloned, err := agent.updateDeviceWithRetry(func(cloned *voltha.Device) error {
 		// Ensure the enums passed in are valid - they will be invalid if they are not set when this function is invoked
 		if s, ok := voltha.ConnectStatus_ConnectStatus_value[connStatus.String()]; ok {
 			log.Debugw("updateDeviceStatus-conn", log.Fields{"ok": ok, "val": s})
@@ -354,94 +487,102 @@ func (agent *DeviceAgent) updateDeviceStatus(operStatus voltha.OperStatus_OperSt
 			cloned.OperStatus = operStatus
 		}
 		log.Debugw("updateDeviceStatus", log.Fields{"deviceId": cloned.Id, "operStatus": cloned.OperStatus, "connectStatus": cloned.ConnectStatus})
-		// Store the device
-		if afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, ""); afterUpdate == nil {
-			agent.lockDevice.Unlock()
-			return status.Errorf(codes.Internal, "%s", agent.deviceId)
-		}
-		agent.lockDevice.Unlock()
-		// Perform the state transition
-		if err := agent.deviceMgr.processTransition(storeDevice, cloned); err != nil {
-			log.Warnw("process-transition-error", log.Fields{"deviceid": agent.deviceId, "error": err})
-			return err
-		}
 		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// updateDeviceStatus moves Oper/ConnectStatus outside of the admin-state-keyed FSM, but
+	// still reports on transitionEvents so subscribers don't have to special-case it.
+	agent.notifyTransition(fsm.Event("oper-status-update"),
+		fsm.State{Admin: before.AdminState, Oper: before.OperStatus, Connect: before.ConnectStatus},
+		fsm.State{Admin: cloned.AdminState, Oper: cloned.OperStatus, Connect: cloned.ConnectStatus})
+	// Perform the state transition
+	if err := agent.deviceMgr.processTransition(before, cloned); err != nil {
+		log.Warnw("process-transition-error", log.Fields{"deviceid": agent.deviceId, "error": err})
+		return err
 	}
+	return nil
+}
+
+// portStateUpdate is one (type, port number, new oper status) tuple to apply as part of a
+// updatePortsState batch.
+type portStateUpdate struct {
+	portType   voltha.Port_PortType
+	portNo     uint32
+	operStatus voltha.OperStatus_OperStatus
 }
 
+// updatePortState updates a single port's operational status. It is a thin wrapper over
+// updatePortsState so that callers touching one port at a time and callers batching several
+// adjacent port updates share the same locking and retry behavior.
 func (agent *DeviceAgent) updatePortState(portType voltha.Port_PortType, portNo uint32, operStatus voltha.OperStatus_OperStatus) error {
-	agent.lockDevice.Lock()
-	//defer agent.lockDevice.Unlock()
-	// Work only on latest data
-	// TODO: Get list of ports from device directly instead of the entire device
-	if storeDevice, err := agent.getDeviceWithoutLock(); err != nil {
-		agent.lockDevice.Unlock()
-		return status.Errorf(codes.NotFound, "%s", agent.deviceId)
-	} else {
-		// clone the device
-		cloned := proto.Clone(storeDevice).(*voltha.Device)
-		// Ensure the enums passed in are valid - they will be invalid if they are not set when this function is invoked
-		if _, ok := voltha.Port_PortType_value[portType.String()]; !ok {
-			agent.lockDevice.Unlock()
-			return status.Errorf(codes.InvalidArgument, "%s", portType)
+	return agent.updatePortsState([]portStateUpdate{{portType: portType, portNo: portNo, operStatus: operStatus}})
+}
+
+// updatePortsState applies a batch of port operational-status updates to the device in a single
+// clone+commit, so adjacent port events (e.g. a PON's sibling ONUs flapping together) cost one
+// clusterDataProxy.Update instead of one per port.
+func (agent *DeviceAgent) updatePortsState(updates []portStateUpdate) error {
+	for _, u := range updates {
+		if _, ok := voltha.Port_PortType_value[u.portType.String()]; !ok {
+			return status.Errorf(codes.InvalidArgument, "%s", u.portType)
 		}
-		for _, port := range cloned.Ports {
-			if port.Type == portType && port.PortNo == portNo {
-				port.OperStatus = operStatus
-				// Set the admin status to ENABLED if the operational status is ACTIVE
-				// TODO: Set by northbound system?
-				if operStatus == voltha.OperStatus_ACTIVE {
-					port.AdminState = voltha.AdminState_ENABLED
+	}
+	agent.lockPorts.Lock()
+	defer agent.lockPorts.Unlock()
+	before, cloned, err := agent.updateDeviceWithRetry(func(cloned *voltha.Device) error {
+		for _, u := range updates {
+			for _, port := range cloned.Ports {
+				if port.Type == u.portType && port.PortNo == u.portNo {
+					port.OperStatus = u.operStatus
+					// Set the admin status to ENABLED if the operational status is ACTIVE
+					// TODO: Set by northbound system?
+					if u.operStatus == voltha.OperStatus_ACTIVE {
+						port.AdminState = voltha.AdminState_ENABLED
+					}
+					break
 				}
-				break
 			}
 		}
-		log.Debugw("portStatusUpdate", log.Fields{"deviceId": cloned.Id})
-		// Store the device
-		if afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, ""); afterUpdate == nil {
-			agent.lockDevice.Unlock()
-			return status.Errorf(codes.Internal, "%s", agent.deviceId)
-		}
-		agent.lockDevice.Unlock()
-		// Perform the state transition
-		if err := agent.deviceMgr.processTransition(storeDevice, cloned); err != nil {
-			log.Warnw("process-transition-error", log.Fields{"deviceid": agent.deviceId, "error": err})
-			return err
-		}
+		log.Debugw("portStatusUpdate", log.Fields{"deviceId": cloned.Id, "count": len(updates)})
 		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// updatePortsState, like updateDeviceStatus, sits outside the admin-state-keyed FSM but still
+	// reports on transitionEvents for observability.
+	agent.notifyTransition(fsm.Event("port-status-update"),
+		fsm.State{Admin: before.AdminState, Oper: before.OperStatus, Connect: before.ConnectStatus},
+		fsm.State{Admin: cloned.AdminState, Oper: cloned.OperStatus, Connect: cloned.ConnectStatus})
+	// Perform the state transition
+	if err := agent.deviceMgr.processTransition(before, cloned); err != nil {
+		log.Warnw("process-transition-error", log.Fields{"deviceid": agent.deviceId, "error": err})
+		return err
 	}
+	return nil
 }
 
 func (agent *DeviceAgent) updatePmConfigs(pmConfigs *voltha.PmConfigs) error {
-	agent.lockDevice.Lock()
-	defer agent.lockDevice.Unlock()
+	agent.lockPmConfigs.Lock()
+	defer agent.lockPmConfigs.Unlock()
 	log.Debug("updatePmConfigs")
-	// Work only on latest data
-	if storeDevice, err := agent.getDeviceWithoutLock(); err != nil {
-		return status.Errorf(codes.NotFound, "%s", agent.deviceId)
-	} else {
-		// clone the device
-		cloned := proto.Clone(storeDevice).(*voltha.Device)
+	_, _, err := agent.updateDeviceWithRetry(func(cloned *voltha.Device) error {
 		cloned.PmConfigs = proto.Clone(pmConfigs).(*voltha.PmConfigs)
-		// Store the device
-		afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, "")
-		if afterUpdate == nil {
-			return status.Errorf(codes.Internal, "%s", agent.deviceId)
-		}
 		return nil
-	}
+	})
+	return err
 }
 
 func (agent *DeviceAgent) addPort(port *voltha.Port) error {
-	agent.lockDevice.Lock()
-	defer agent.lockDevice.Unlock()
+	_, span := device.StartAgentSpan(context.Background(), "addPort", agent.deviceId, agent.lastData.ParentId)
+	defer span.End()
+
+	agent.lockPorts.Lock()
+	defer agent.lockPorts.Unlock()
 	log.Debugw("addPort", log.Fields{"deviceId": agent.deviceId})
-	// Work only on latest data
-	if storeDevice, err := agent.getDeviceWithoutLock(); err != nil {
-		return status.Errorf(codes.NotFound, "%s", agent.deviceId)
-	} else {
-		// clone the device
-		cloned := proto.Clone(storeDevice).(*voltha.Device)
+	_, _, err := agent.updateDeviceWithRetry(func(cloned *voltha.Device) error {
 		if cloned.Ports == nil {
 			//	First port
 			log.Debugw("addPort-first-port-to-add", log.Fields{"deviceId": agent.deviceId})
@@ -454,25 +595,16 @@ func (agent *DeviceAgent) addPort(port *voltha.Port) error {
 			cp.AdminState = voltha.AdminState_ENABLED
 		}
 		cloned.Ports = append(cloned.Ports, cp)
-		// Store the device
-		afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, "")
-		if afterUpdate == nil {
-			return status.Errorf(codes.Internal, "%s", agent.deviceId)
-		}
 		return nil
-	}
+	})
+	return err
 }
 
 func (agent *DeviceAgent) addPeerPort(port *voltha.Port_PeerPort) error {
-	agent.lockDevice.Lock()
-	defer agent.lockDevice.Unlock()
+	agent.lockPorts.Lock()
+	defer agent.lockPorts.Unlock()
 	log.Debug("addPeerPort")
-	// Work only on latest data
-	if storeDevice, err := agent.getDeviceWithoutLock(); err != nil {
-		return status.Errorf(codes.NotFound, "%s", agent.deviceId)
-	} else {
-		// clone the device
-		cloned := proto.Clone(storeDevice).(*voltha.Device)
+	_, cloned, err := agent.updateDeviceWithRetry(func(cloned *voltha.Device) error {
 		// Get the peer port on the device based on the port no
 		for _, peerPort := range cloned.Ports {
 			if peerPort.PortNo == port.PortNo { // found port
@@ -482,60 +614,32 @@ func (agent *DeviceAgent) addPeerPort(port *voltha.Port_PeerPort) error {
 				break
 			}
 		}
-		//To track an issue when adding peer-port.
-		log.Debugw("before-peer-added", log.Fields{"device": cloned})
-		// Store the device
-		afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, "")
-		if afterUpdate == nil {
-			return status.Errorf(codes.Internal, "%s", agent.deviceId)
-		}
-		//To track an issue when adding peer-port.
-		if d, ok := afterUpdate.(*voltha.Device); ok {
-			log.Debugw("after-peer-added", log.Fields{"device": d})
-		} else {
-			log.Debug("after-peer-added-incorrect-type", log.Fields{"type": reflect.ValueOf(afterUpdate).Type()})
-		}
-
 		return nil
+	})
+	if err != nil {
+		return err
 	}
+	//To track an issue when adding peer-port.
+	log.Debugw("after-peer-added", log.Fields{"device": cloned})
+	return nil
 }
 
-// TODO: A generic device update by attribute
-func (agent *DeviceAgent) updateDeviceAttribute(name string, value interface{}) {
+// updateDeviceAttribute updates a single named field on the device via the generated, typed
+// SetDeviceField setter table (see device_fields.go) instead of reflect, so unknown field names
+// and type-mismatched values surface as an error rather than being silently skipped.
+func (agent *DeviceAgent) updateDeviceAttribute(name string, value interface{}) error {
 	agent.lockDevice.Lock()
 	defer agent.lockDevice.Unlock()
 	if value == nil {
-		return
-	}
-	var storeDevice *voltha.Device
-	var err error
-	if storeDevice, err = agent.getDeviceWithoutLock(); err != nil {
-		return
-	}
-	updated := false
-	s := reflect.ValueOf(storeDevice).Elem()
-	if s.Kind() == reflect.Struct {
-		// exported field
-		f := s.FieldByName(name)
-		if f.IsValid() && f.CanSet() {
-			switch f.Kind() {
-			case reflect.String:
-				f.SetString(value.(string))
-				updated = true
-			case reflect.Uint32:
-				f.SetUint(uint64(value.(uint32)))
-				updated = true
-			case reflect.Bool:
-				f.SetBool(value.(bool))
-				updated = true
-			}
-		}
+		return nil
 	}
-	log.Debugw("update-field-status", log.Fields{"deviceId": storeDevice.Id, "name": name, "updated": updated})
-	//	Save the data
-	cloned := proto.Clone(storeDevice).(*voltha.Device)
-	if afterUpdate := agent.clusterDataProxy.Update("/devices/"+agent.deviceId, cloned, false, ""); afterUpdate == nil {
-		log.Warnw("attribute-update-failed", log.Fields{"attribute": name, "value": value})
+	_, cloned, err := agent.updateDeviceWithRetry(func(cloned *voltha.Device) error {
+		return SetDeviceField(cloned, name, value)
+	})
+	if err != nil {
+		log.Warnw("attribute-update-failed", log.Fields{"attribute": name, "value": value, "error": err})
+		return err
 	}
-	return
+	log.Debugw("update-field-status", log.Fields{"deviceId": cloned.Id, "name": name})
+	return nil
 }
\ No newline at end of file
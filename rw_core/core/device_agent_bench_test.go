@@ -0,0 +1,102 @@
+/*
+ * Copyright 2019-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package core
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/opencord/voltha-go/db/model"
+	"github.com/opencord/voltha-go/protos/voltha"
+	"github.com/opencord/voltha-go/rw_core/config"
+	"github.com/opencord/voltha-lib-go/v3/pkg/db"
+	mock_etcd "github.com/opencord/voltha-lib-go/v3/pkg/mocks/etcd"
+	"github.com/phayes/freeport"
+)
+
+// newBenchDeviceAgent wires a DeviceAgent up to a real model.Proxy backed by an embedded etcd
+// instance, the same way DATest does in rw_core/core/device, but leaves adapterProxy and
+// deviceMgr nil since addPort never touches either.
+func newBenchDeviceAgent(b *testing.B) (*DeviceAgent, func()) {
+	kvClientPort, err := freeport.GetFreePort()
+	if err != nil {
+		b.Fatal(err)
+	}
+	peerPort, err := freeport.GetFreePort()
+	if err != nil {
+		b.Fatal(err)
+	}
+	etcdServer := mock_etcd.StartEtcdServer(mock_etcd.MKConfig("voltha.rwcore.da.bench", kvClientPort, peerPort, "voltha.rwcore.da.bench.etcd", "error"))
+	if etcdServer == nil {
+		b.Fatal("embedded etcd server failed to start")
+	}
+
+	cfg := config.NewRWCoreFlags()
+	cfg.KVStorePort = kvClientPort
+	client, err := config.NewKVClient(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	backend := &db.Backend{
+		Client:     client,
+		StoreType:  cfg.KVStoreType,
+		Host:       cfg.KVStoreHost,
+		Port:       cfg.KVStorePort,
+		Timeout:    cfg.KVStoreTimeout,
+		PathPrefix: cfg.KVStoreDataPrefix,
+	}
+	proxy := model.NewProxy(backend, "/")
+
+	agent := newDeviceAgent(nil, &voltha.Device{Id: "bench-device"}, nil, proxy)
+	agent.start(nil)
+
+	return agent, func() { etcdServer.Stop() }
+}
+
+// BenchmarkAddPortConcurrent measures the throughput of addPort - the lockPorts-guarded,
+// CAS-retrying path shared with updatePortState/updatePortsState - under concurrent callers, to
+// track regressions in the finer-grained locking introduced to replace the single coarse
+// lockDevice mutex.
+func BenchmarkAddPortConcurrent(b *testing.B) {
+	agent, cleanup := newBenchDeviceAgent(b)
+	defer cleanup()
+
+	b.ResetTimer()
+	var wg sync.WaitGroup
+	portNo := uint32(0)
+	var portNoMu sync.Mutex
+	wg.Add(b.N)
+	for i := 0; i < b.N; i++ {
+		go func() {
+			defer wg.Done()
+			portNoMu.Lock()
+			portNo++
+			no := portNo
+			portNoMu.Unlock()
+			port := &voltha.Port{
+				PortNo:     no,
+				Label:      fmt.Sprintf("port-%d", no),
+				Type:       voltha.Port_ETHERNET_UNI,
+				OperStatus: voltha.OperStatus_ACTIVE,
+			}
+			if err := agent.addPort(port); err != nil {
+				b.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2019-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/opencord/voltha-go/common/log"
+	"github.com/opencord/voltha-go/rw_core/core/fsm"
+)
+
+// opIDContextKey is the context key an NBI request handler sets via WithOpID so a retried call
+// (same opID) can be recognized as a replay rather than a brand new request.
+type opIDContextKey struct{}
+
+// WithOpID returns a context carrying opID, so it propagates down to the DeviceAgent operation
+// it is meant to deduplicate.
+func WithOpID(ctx context.Context, opID string) context.Context {
+	return context.WithValue(ctx, opIDContextKey{}, opID)
+}
+
+// OpIDFromContext returns the opID WithOpID attached to ctx, if any.
+func OpIDFromContext(ctx context.Context) (string, bool) {
+	opID, ok := ctx.Value(opIDContextKey{}).(string)
+	return opID, ok
+}
+
+// inFlightOp tracks one still-running (deviceId, opType) operation; callers that join an
+// in-flight op wait on done and then read err.
+type inFlightOp struct {
+	opID string
+	done chan struct{}
+	err  error
+}
+
+// opRegistry deduplicates adapter operations per (deviceId, opType): a second caller for an
+// opType already running joins the first call's result instead of issuing a duplicate adapter
+// RPC, and an opType whose last opID already completed short-circuits a replay without calling
+// the adapter again.
+type opRegistry struct {
+	mu        sync.Mutex
+	inFlight  map[fsm.Event]*inFlightOp
+	completed map[fsm.Event]string // opType -> last completed opID
+}
+
+func newOpRegistry() *opRegistry {
+	return &opRegistry{
+		inFlight:  make(map[fsm.Event]*inFlightOp),
+		completed: make(map[fsm.Event]string),
+	}
+}
+
+// runDedupedOp runs fn under deduplication for (event, opID-from-ctx):
+//   - if event's last completed opID equals this call's opID, fn is skipped entirely (replay).
+//   - if event is already in flight, this call blocks on the in-flight caller's result instead of
+//     running fn again.
+//   - otherwise fn runs on this goroutine and runDedupedOp waits for it unconditionally, even
+//     past ctx being cancelled: the caller (e.g. enableDevice) holds agent.lockDevice for exactly
+//     as long as this call takes, so returning early while fn kept running in the background
+//     would let the caller's deferred Unlock fire while fn is still calling
+//     agent.clusterDataProxy.Update/agent.deviceMgr.processTransition, letting a second caller's
+//     operation run concurrently against the same device. fn still receives ctx and is expected
+//     to check ctx.Err() once its adapter call returns, skipping the model-update commit instead
+//     of racing a fresh one in.
+func (agent *DeviceAgent) runDedupedOp(ctx context.Context, event fsm.Event, fn func(ctx context.Context) error) error {
+	opID, hasOpID := OpIDFromContext(ctx)
+	if !hasOpID {
+		opID = CreateDeviceId()
+	}
+
+	agent.ops.mu.Lock()
+	if agent.ops.completed[event] == opID {
+		agent.ops.mu.Unlock()
+		log.Debugw("op-replay-short-circuit", log.Fields{"deviceId": agent.deviceId, "event": event, "opId": opID})
+		return nil
+	}
+	if op, running := agent.ops.inFlight[event]; running {
+		agent.ops.mu.Unlock()
+		log.Debugw("op-join-in-flight", log.Fields{"deviceId": agent.deviceId, "event": event, "opId": opID})
+		<-op.done
+		return op.err
+	}
+	op := &inFlightOp{opID: opID, done: make(chan struct{})}
+	agent.ops.inFlight[event] = op
+	agent.ops.mu.Unlock()
+
+	err := fn(ctx)
+
+	agent.ops.mu.Lock()
+	delete(agent.ops.inFlight, event)
+	if err == nil {
+		agent.ops.completed[event] = opID
+	}
+	agent.ops.mu.Unlock()
+
+	op.err = err
+	close(op.done)
+	return err
+}
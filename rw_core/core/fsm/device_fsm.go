@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fsm models the admin/oper/connect state transitions a device goes through as it is
+// enabled, disabled, rebooted and deleted. It replaces the ad-hoc precondition checks that used
+// to be inlined in DeviceAgent's enable/disable/reboot/delete methods with a table of allowed
+// transitions plus guard and action hooks, so the set of legal device states - and what happens
+// when moving between them - lives in one place instead of being re-derived per call site.
+package fsm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencord/voltha-go/protos/voltha"
+)
+
+// Event is a request to move a device from its current state to a new one.
+type Event string
+
+const (
+	EventEnable  Event = "enable"
+	EventDisable Event = "disable"
+	EventReboot  Event = "reboot"
+	EventDelete  Event = "delete"
+)
+
+// State is the admin/oper/connect triple a device sits in at any given time. Only AdminState is
+// used to key transitions today; OperStatus/ConnectStatus are carried along as the values a
+// transition's actions are expected to set.
+type State struct {
+	Admin   voltha.AdminState_AdminState
+	Oper    voltha.OperStatus_OperStatus
+	Connect voltha.ConnectStatus_ConnectStatus
+}
+
+// Guard vets whether a transition may proceed given the device's current state; returning an
+// error aborts the transition before any action runs.
+type Guard func(current State) error
+
+// Action performs the side effect associated with a transition (typically an adapter RPC) and
+// returns the State the device should move to on success; Dispatch does not commit that State,
+// the caller does.
+type Action func(ctx context.Context, current State) (State, error)
+
+// transition is one (fromAdmin, event) -> action mapping, guarded before it runs.
+type transition struct {
+	from  voltha.AdminState_AdminState
+	event Event
+	guard Guard
+	action Action
+}
+
+// DeviceFSM holds every legal transition for a device's admin/oper/connect state and dispatches
+// events against the table instead of letting callers re-implement the precondition logic.
+type DeviceFSM struct {
+	transitions []transition
+}
+
+// NewDeviceFSM returns an FSM with no transitions registered; callers build up the table with
+// AddTransition before calling Dispatch.
+func NewDeviceFSM() *DeviceFSM {
+	return &DeviceFSM{}
+}
+
+// AddTransition registers that, from admin state "from", event "event" is legal, subject to
+// guard (which may be nil), and runs action to compute the resulting state.
+func (f *DeviceFSM) AddTransition(from voltha.AdminState_AdminState, event Event, guard Guard, action Action) {
+	f.transitions = append(f.transitions, transition{from: from, event: event, guard: guard, action: action})
+}
+
+// TransitionError reports that no registered transition matches (current.Admin, event).
+type TransitionError struct {
+	From  voltha.AdminState_AdminState
+	Event Event
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("no-transition-for-event:%s-from-state:%s", e.Event, e.From)
+}
+
+// Dispatch finds the transition matching (current.Admin, event), runs its guard and action, and
+// returns the resulting State. It returns *TransitionError if no such transition is registered.
+func (f *DeviceFSM) Dispatch(ctx context.Context, current State, event Event) (State, error) {
+	for _, t := range f.transitions {
+		if t.from != current.Admin || t.event != event {
+			continue
+		}
+		if t.guard != nil {
+			if err := t.guard(current); err != nil {
+				return current, err
+			}
+		}
+		return t.action(ctx, current)
+	}
+	return current, &TransitionError{From: current.Admin, Event: event}
+}
@@ -0,0 +1,88 @@
+// +build ignore
+
+/*
+ * Copyright 2019-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// gen_device_fields regenerates device_fields.go by walking the exported fields of
+// voltha.Device with reflection and emitting one typed setter per field. Run it with
+// `go generate` (see the directive in device_fields.go) whenever the voltha.Device proto gains,
+// renames, or retypes a field. It emits only the code: the license header and the doc comments
+// that device_fields.go carries are not written by this generator, so a regenerated file lacks them.
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/opencord/voltha-go/protos/voltha"
+)
+
+// goType renders the Go type a setter for field f should accept, matching how proto-gen-go
+// already rendered that field on the voltha.Device struct. t.Elem().String() already comes back
+// package-qualified (e.g. "voltha.Port"), so a pointer/slice element must keep that qualifier
+// rather than have it stripped - this package is "core", not "voltha".
+func goType(f reflect.StructField) string {
+	t := f.Type
+	switch {
+	case t.Kind() == reflect.Ptr:
+		return "*" + t.Elem().String()
+	case t.Kind() == reflect.Slice:
+		return "[]" + goType(reflect.StructField{Type: t.Elem()})
+	default:
+		return t.String()
+	}
+}
+
+func main() {
+	deviceType := reflect.TypeOf(voltha.Device{})
+
+	var b strings.Builder
+	b.WriteString("// Code generated by gen_device_fields.go; DO NOT EDIT.\n")
+	b.WriteString("//go:generate go run gen_device_fields.go\n\n")
+	b.WriteString("package core\n\n")
+	b.WriteString("import (\n\t\"fmt\"\n\n\t\"github.com/golang/protobuf/ptypes/any\"\n\t\"github.com/opencord/voltha-go/protos/voltha\"\n)\n\n")
+	b.WriteString("type deviceFieldSetter func(device *voltha.Device, value interface{}) error\n\n")
+	b.WriteString("var deviceFieldSetters = map[string]deviceFieldSetter{\n")
+
+	for i := 0; i < deviceType.NumField(); i++ {
+		f := deviceType.Field(i)
+		if f.PkgPath != "" || !strings.Contains(string(f.Tag), "protobuf:") {
+			continue // unexported or non-proto field (e.g. XXX_ bookkeeping)
+		}
+		typ := goType(f)
+		fmt.Fprintf(&b, "\t%q: func(device *voltha.Device, value interface{}) error {\n", f.Name)
+		fmt.Fprintf(&b, "\t\tv, ok := value.(%s)\n", typ)
+		fmt.Fprintf(&b, "\t\tif !ok {\n\t\t\treturn fmt.Errorf(\"field-type-mismatch: %s wants %s, got %%T\", value)\n\t\t}\n", f.Name, typ)
+		fmt.Fprintf(&b, "\t\tdevice.%s = v\n\t\treturn nil\n\t},\n", f.Name)
+	}
+	b.WriteString("}\n\n")
+	b.WriteString("func SetDeviceField(device *voltha.Device, name string, value interface{}) error {\n")
+	b.WriteString("\tsetter, ok := deviceFieldSetters[name]\n")
+	b.WriteString("\tif !ok {\n\t\treturn fmt.Errorf(\"unknown-device-field: %s\", name)\n\t}\n")
+	b.WriteString("\treturn setter(device, value)\n}\n")
+
+	out, err := format.Source([]byte(b.String()))
+	if err != nil {
+		// Emit the unformatted source on failure so the cause is inspectable instead of hidden.
+		out = []byte(b.String())
+	}
+	if err := os.WriteFile("device_fields.go", out, 0644); err != nil {
+		panic(err)
+	}
+}